@@ -1,8 +1,11 @@
 package queryfilter
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 )
 
 // Clause holds the fields that are parsed from the original QueryFilter struct fields.
@@ -11,6 +14,9 @@ import (
 // as an operator is defined as a function that takes a Clause and returns the query segment,
 // the values to be used in the query, and optionally an error.
 type Clause struct {
+	// Field holds the name of the struct field the clause originates from.
+	Field string
+
 	// Col describes the database column the operation works on.
 	Col string
 
@@ -22,6 +28,80 @@ type Clause struct {
 
 	// cached reflected value of the Val field
 	reflectedValue reflect.Value
+
+	// parameters passed to the operator using the tag, eg: `path=country`
+	params map[string]string
+
+	// compare nil values using IS NULL rather than skipping the clause, see WithNilAsIsNull
+	nilIsNull bool
+
+	// options the query is built with
+	opts *Opts
+
+	// how the segment returned by the operator is completed, set while rendering the clause, see FullSegment
+	segment *segmentState
+
+	// error reading a composite value (eg: a struct) as a single value, returned when rendering the clause
+	// unless the operator interprets the value itself, see compositeOperator
+	valueErr error
+}
+
+// FullSegment marks the query segment returned by the operator of the clause as complete. By default the column
+// is prepended to the segment returned by an operator (eg: `= ?` results in `age = ?`), which doesn't
+// work for operators that need the column elsewhere, like within a function call.
+//
+// Instead, the column of a full segment is placed wherever the segment contains the ColumnToken, eg:
+//
+//	RegisterOperator("fts", func(c Clause) (string, []any, error) {
+//		return c.FullSegment("to_tsvector({col}) @@ plainto_tsquery(?)"), []any{c.Val}, nil
+//	})
+//
+// results in the query segment `to_tsvector(title) @@ plainto_tsquery(?)`. A full segment is not required
+// to include the column at all (eg: `1=1`), while an empty full segment results in the clause being skipped.
+//
+// The column is placed when calling FullSegment, so only the template passed to it is affected: text the operator
+// adds to the segment afterwards (eg: a subquery written into the template) is left as is, even when it happens
+// to contain the ColumnToken. The clause records the segment is complete, so an operator decorating the segment
+// of another operator (eg: wrapping the segment of `in` in parentheses) keeps it complete, without repeating
+// the column. Operators are expected to call it on the clause they're passed.
+func (c *Clause) FullSegment(sql string) string {
+	c.markFull()
+	return strings.ReplaceAll(sql, ColumnToken, c.Col)
+}
+
+// markFull records the segment returned by the operator of the clause is complete, without placing the column,
+// for segments embedding text that's written into the query as is (eg: a subquery), see FullSegment.
+func (c *Clause) markFull() {
+	if c.segment != nil {
+		c.segment.full = true
+	}
+}
+
+// Param returns the value of a parameter passed to the operator using the tag, and whether it's set.
+// Parameters are the key=value segments following the operator, eg: `filter:"metadata,op=json-path,path=country"`
+// results in the parameter "path" having the value "country".
+func (c *Clause) Param(key string) (string, bool) {
+	value, ok := c.params[key]
+	return value, ok
+}
+
+// Context returns the context passed to ToSQLContext, or the background context otherwise.
+func (c *Clause) Context() context.Context {
+	if c.opts == nil || c.opts.ctx == nil {
+		return context.Background()
+	}
+
+	return c.opts.ctx
+}
+
+// options returns the options the query is built with, or the default options for clauses
+// constructed outside of ToSQL (eg: when testing an operator).
+func (c *Clause) options() *Opts {
+	if c.opts == nil {
+		return DefaultOpts()
+	}
+
+	return c.opts
 }
 
 // AssertTypeOneOf checks if the Clause's reflected value is one of the provided kinds.
@@ -41,9 +121,67 @@ func (c *Clause) AssertTypeOneOf(kinds ...reflect.Kind) error {
 	}
 
 	return fmt.Errorf(
-		"expected %s; got %s for operation %s",
-		summarize(kinds...),
+		"%w: expected %s; got %s for operation %s",
+		ErrTypeMismatch,
+		FormatKinds(kinds...),
 		actualKind,
 		c.Op,
 	)
 }
+
+// wrapErr prefixes the error with the struct field and column the clause originates from,
+// so it's clear which field in the filter struct caused the error.
+func (c *Clause) wrapErr(err error) error {
+	// groups don't have a column of their own
+	if c.Col == "" {
+		return fmt.Errorf("field %s: %w", c.Field, err)
+	}
+
+	return fmt.Errorf("field %s (column %s): %w", c.Field, c.Col, err)
+}
+
+// readValue reads the (raw) value of the field into the clause.
+func (c *Clause) readValue(rawValue reflect.Value) error {
+	// store the dereferenced reflected value for later use
+	c.reflectedValue = derefIfApplicable(rawValue)
+
+	// compare slices using in rather than eq, when enabled
+	if c.options().AutoInForSlices {
+		kind := c.reflectedValue.Kind()
+		if promoted, ok := slicePromotions[c.Op]; ok && (kind == reflect.Slice || kind == reflect.Array) {
+			c.Op = promoted
+		}
+	}
+
+	val, err := readValue(rawValue)
+	if err != nil {
+		if !isComposite(c.reflectedValue) {
+			return c.wrapErr(err)
+		}
+
+		// whether the operator interprets the value itself isn't known until the clause is rendered
+		c.valueErr = err
+		val = readComposite(c.reflectedValue)
+	}
+
+	// skip zero times like nil values, when enabled
+	if t, ok := val.(time.Time); ok && t.IsZero() && c.options().ZeroTimeAsNull {
+		val = nil
+	}
+
+	// skip empty slices like nil values, when enabled
+	inOperation := c.Op == "in" || c.Op == "not-in" || c.Op == "iin"
+	if elems, ok := val.([]any); ok && len(elems) == 0 && inOperation && c.options().SkipEmptySlices {
+		val = nil
+	}
+
+	// compare nil values using is-null rather than skipping them, when enabled
+	if isNull, ok := nullPromotions[c.Op]; ok && val == nil && c.nilIsNull {
+		c.Op = "is-null"
+		c.reflectedValue = reflect.ValueOf(isNull)
+		val = isNull
+	}
+
+	c.Val = val
+	return nil
+}
@@ -0,0 +1,345 @@
+package queryfilter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Describer is a function that receives a clause and the values that are bound by its operator,
+// and returns a human readable description of the clause, eg: "age is greater than 18".
+//
+// Describers are registered by operator name in the global Describers map and are used by Describe.
+type Describer func(c Clause, args []any) string
+
+// Describers is a globally defined map of describers, keyed by the name of the operator they describe.
+// Operators without a describer are described using their name, eg: "age my-operator 18".
+//
+// Use RegisterDescriber to add describers once queries may be described concurrently,
+// as writing to the map directly isn't safe for concurrent use.
+var Describers = map[string]Describer{}
+
+// describersMu guards the globally registered describers.
+var describersMu sync.RWMutex
+
+// RegisterDescriber registers a describer for the operator with the given name.
+// It's safe to use while filters are being described.
+//
+// Note that calling this function multiple times with the same name will
+// overwrite the describer previously registered to the operator without warning.
+func RegisterDescriber(name string, d Describer) {
+	describersMu.Lock()
+	defer describersMu.Unlock()
+
+	Describers[name] = d
+}
+
+// registeredDescriber returns the describer registered for the operator with the given name.
+func registeredDescriber(name string) (Describer, bool) {
+	describersMu.RLock()
+	defer describersMu.RUnlock()
+
+	d, ok := Describers[name]
+	return d, ok
+}
+
+// DescribeAs is a shorthand function for creating describers that place a phrase between the column
+// and its values. Multiple values are joined using the conjunction.
+//
+// eg: DescribeAs("is between", "and") describes a clause as "price is between 10 and 30".
+func DescribeAs(phrase, conjunction string) Describer {
+	return func(c Clause, args []any) string {
+		values := make([]describedValue, len(args))
+		for i, arg := range args {
+			values[i] = describedValue{arg}
+		}
+
+		return fmt.Sprintf("%s %s %s", c.Col, phrase, summarizeWith(conjunction, values...))
+	}
+}
+
+// describeInAs works like DescribeAs for the in operators, describing an empty slice as the operator renders it
+// following the EmptyInBehavior: `in` matches nothing either way, while `not-in` matches all rows when the slice is
+// treated as an empty set, but nothing when it's compared against NULL.
+func describeInAs(phrase string, negated bool) Describer {
+	describe := DescribeAs(phrase, "or")
+
+	return func(c Clause, args []any) string {
+		if len(args) > 0 {
+			return describe(c, args)
+		}
+
+		if negated && c.options().EmptyInBehavior == EmptyInNull {
+			return fmt.Sprintf("%s %s NULL, which matches nothing", c.Col, phrase)
+		}
+
+		return fmt.Sprintf("%s %s nothing", c.Col, phrase)
+	}
+}
+
+func init() {
+	// register describers for the built in operators
+	for name, describe := range map[string]Describer{
+		"eq":                DescribeAs("is", "and"),
+		"gt":                DescribeAs("is greater than", "and"),
+		"gte":               DescribeAs("is at least", "and"),
+		"lte":               DescribeAs("is at most", "and"),
+		"lt":                DescribeAs("is less than", "and"),
+		"neq":               DescribeAs("is not", "and"),
+		"not-eq":            DescribeAs("is not", "and"),
+		"before":            DescribeAs("is before", "and"),
+		"after":             DescribeAs("is after", "and"),
+		"on-or-before":      DescribeAs("is on or before", "and"),
+		"on-or-after":       DescribeAs("is on or after", "and"),
+		"in":                describeInAs("is one of", false),
+		"not-in":            describeInAs("is none of", true),
+		"iin":               describeInAs("is one of (ignoring case)", false),
+		"between":           DescribeAs("is between", "and"),
+		"range":             DescribeAs("is between", "and"),
+		"json-contains":     DescribeAs("contains", "and"),
+		"array-overlap":     DescribeAs("overlaps with", "and"),
+		"array-contains":    DescribeAs("contains", "and"),
+		"any":               DescribeAs("is one of", "or"),
+		"fts":               DescribeAs("matches", "and"),
+		"regexp":            DescribeAs("matches pattern", "and"),
+		"ieq":               DescribeAs("is (ignoring case)", "and"),
+		"eq-col":            describeColumnAs("is"),
+		"neq-col":           describeColumnAs("is not"),
+		"gt-col":            describeColumnAs("is greater than"),
+		"gte-col":           describeColumnAs("is at least"),
+		"lt-col":            describeColumnAs("is less than"),
+		"lte-col":           describeColumnAs("is at most"),
+		"length-eq":         DescribeAs("has a length of", "and"),
+		"length-gte":        DescribeAs("has a length of at least", "and"),
+		"length-lte":        DescribeAs("has a length of at most", "and"),
+		"distinct-from":     DescribeAs("is distinct from", "and"),
+		"not-distinct-from": DescribeAs("is not distinct from", "and"),
+		"gt-all":            describeSubqueryAs("is greater than all of"),
+		"lt-all":            describeSubqueryAs("is less than all of"),
+		"eq-any":            describeSubqueryAs("is any of"),
+		"range-open":        describeOpenRange,
+	} {
+		RegisterDescriber(name, describe)
+	}
+
+	RegisterDescriber("is-null", func(c Clause, _ []any) string {
+		if c.reflectedValue.Bool() {
+			return fmt.Sprintf("%s is empty", c.Col)
+		}
+
+		return fmt.Sprintf("%s is not empty", c.Col)
+	})
+
+	RegisterDescriber("not-null", func(c Clause, _ []any) string {
+		if c.reflectedValue.Bool() {
+			return fmt.Sprintf("%s is not empty", c.Col)
+		}
+
+		return fmt.Sprintf("%s is empty", c.Col)
+	})
+
+	RegisterDescriber("is-true", func(c Clause, _ []any) string {
+		return fmt.Sprintf("%s is %t", c.Col, c.reflectedValue.Bool())
+	})
+
+	RegisterDescriber("is-false", func(c Clause, _ []any) string {
+		return fmt.Sprintf("%s is %t", c.Col, !c.reflectedValue.Bool())
+	})
+
+	RegisterDescriber("before-now", func(c Clause, _ []any) string {
+		if c.reflectedValue.Bool() {
+			return fmt.Sprintf("%s is in the past", c.Col)
+		}
+
+		return fmt.Sprintf("%s is not in the past", c.Col)
+	})
+
+	RegisterDescriber("after-now", func(c Clause, _ []any) string {
+		if c.reflectedValue.Bool() {
+			return fmt.Sprintf("%s is in the future", c.Col)
+		}
+
+		return fmt.Sprintf("%s is not in the future", c.Col)
+	})
+
+	RegisterDescriber("json-path", func(c Clause, args []any) string {
+		path, _ := c.Param("path")
+		c.Col = fmt.Sprintf("%s.%s", c.Col, path)
+		return DescribeAs("is", "and")(c, args)
+	})
+
+	RegisterDescriber("groups", func(c Clause, _ []any) string {
+		return fmt.Sprintf("%s matches one of %d groups", c.Col, c.reflectedValue.Len())
+	})
+
+	RegisterDescriber("raw", func(c Clause, _ []any) string {
+		raw, _ := c.Val.(Raw)
+		return fmt.Sprintf("%s matches %s", c.Col, raw.SQL)
+	})
+
+	RegisterDescriber("group", describeGroupAs("(%s)"))
+	RegisterDescriber("not-group", describeGroupAs("not (%s)"))
+
+	RegisterDescriber("exists", func(c Clause, _ []any) string {
+		return fmt.Sprintf("%s exist", c.Col)
+	})
+}
+
+// ClauseDescription describes a single clause a filter results in, along with the values it binds.
+type ClauseDescription struct {
+	// Column the clause filters on.
+	Column string
+
+	// Operator the clause uses, eg: in.
+	Operator string
+
+	// Values holds the values bound by the clause, as passed to the database.
+	Values []any
+
+	// Text holds the human readable description of the clause, eg: "status is one of todo or doing".
+	Text string
+}
+
+// String returns the human readable description of the clause.
+func (d ClauseDescription) String() string {
+	return d.Text
+}
+
+// Describe takes a filter struct and returns a description of each of the clauses it results in,
+// holding the column, operator and the concrete values it binds (rather than placeholders).
+// This is useful for debugging or audit logging what a filter resolves to.
+func Describe(f any, fns ...OptFn) ([]ClauseDescription, error) {
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	clauses, err := buildClauses(f, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make([]ClauseDescription, 0, len(clauses))
+	for _, c := range clauses {
+		// skip nil values
+		if c.Val == nil {
+			continue
+		}
+
+		operator, err := lookupOperator(c, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		// run the operator to validate the clause and obtain the values it binds
+		_, args, skip, err := renderOperator(operator, c)
+		if err != nil {
+			return nil, c.wrapErr(err)
+		}
+
+		if skip {
+			continue
+		}
+
+		// bind into a new slice, as the args may be owned by the caller (eg: the args of a Raw value)
+		bound := make([]any, len(args))
+		for i, arg := range args {
+			bound[i] = bindArg(arg, c.Col, opts)
+		}
+
+		describe, ok := registeredDescriber(c.Op)
+		if !ok {
+			describe = DescribeAs(c.Op, "and")
+		}
+
+		descriptions = append(descriptions, ClauseDescription{
+			Column:   c.Col,
+			Operator: c.Op,
+			Values:   bound,
+			Text:     describe(c, bound),
+		})
+	}
+
+	return descriptions, nil
+}
+
+// DescribeText takes a filter struct and returns a human readable description of the clauses it
+// results in, eg: "age is between 18 and 65 and status is one of todo or doing".
+// This is useful for showing the active filters to the user.
+func DescribeText(f any, fns ...OptFn) (string, error) {
+	descriptions, err := Describe(f, fns...)
+	if err != nil {
+		return "", err
+	}
+
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	texts := make([]string, len(descriptions))
+	for i, d := range descriptions {
+		texts[i] = d.Text
+	}
+
+	sep := fmt.Sprintf(" %s ", strings.ToLower(string(opts.ChainingStrategy)))
+	return strings.Join(texts, sep), nil
+}
+
+// describeColumnAs describes the column comparisons, placing the phrase between both columns,
+// eg: "updated_at is greater than created_at".
+func describeColumnAs(phrase string) Describer {
+	return func(c Clause, _ []any) string {
+		return fmt.Sprintf("%s %s %v", c.Col, phrase, c.Val)
+	}
+}
+
+// describeSubqueryAs describes the subquery comparisons, eg: "price is greater than all of (SELECT ...)".
+func describeSubqueryAs(phrase string) Describer {
+	return func(c Clause, _ []any) string {
+		query := fmt.Sprint(c.Val)
+		if subquery, ok := c.Val.(Subquery); ok {
+			query = subquery.Query
+		}
+		return fmt.Sprintf("%s %s (%s)", c.Col, phrase, query)
+	}
+}
+
+// describeOpenRange describes the bounds that are present, eg: "created is at least 2023-01-01".
+func describeOpenRange(c Clause, args []any) string {
+	if len(args) == 2 {
+		return DescribeAs("is between", "and")(c, args)
+	}
+
+	if bounds, err := readBounds(c); err == nil && bounds[0] == nil {
+		return DescribeAs("is at most", "and")(c, args)
+	}
+
+	return DescribeAs("is at least", "and")(c, args)
+}
+
+// describeGroupAs describes the clauses of the group, formatted using the format, eg: "not (status is done)".
+func describeGroupAs(format string) Describer {
+	return func(c Clause, _ []any) string {
+		text, err := DescribeText(c.Val, func(o *Opts) { *o = *groupOpts(c, groupStrategy(c)) })
+		if err != nil {
+			return fmt.Sprintf("%s matches a group", c.Field)
+		}
+
+		return fmt.Sprintf(format, text)
+	}
+}
+
+// describedValue formats a bound value for use in a description.
+type describedValue struct {
+	v any
+}
+
+func (d describedValue) String() string {
+	if t, ok := d.v.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+
+	return fmt.Sprint(d.v)
+}
@@ -0,0 +1,148 @@
+package queryfilter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	type filter struct {
+		Ages     []int    `filter:"age,op=between"`
+		Statuses []string `filter:"status,op=in"`
+		Title    *bool    `filter:"title,op=is-null"`
+		Name     *string  `filter:"name"`
+	}
+
+	empty := true
+	f := filter{
+		Ages:     []int{18, 65},
+		Statuses: []string{"todo", "doing"},
+		Title:    &empty,
+	}
+
+	d, err := DescribeText(f)
+	assert.Nil(t, err)
+	assert.Equal(t, "age is between 18 and 65 and status is one of todo or doing and title is empty", d)
+
+	d, err = DescribeText(f, WithChainingStrategy(ChainingStrategyOr))
+	assert.Nil(t, err)
+	assert.Equal(t, "age is between 18 and 65 or status is one of todo or doing or title is empty", d)
+}
+
+func TestDescribeClauses(t *testing.T) {
+	type filter struct {
+		Statuses *[]string  `filter:"status,op=in"`
+		MinPoint *int       `filter:"story_points,op=gte"`
+		DueBy    *time.Time `filter:"due_date,op=before"`
+		Name     *string    `filter:"name"`
+	}
+
+	statuses, points := []string{"todo", "doing"}, 2
+	due := time.Date(2023, 5, 5, 12, 30, 15, 123, time.UTC)
+	f := filter{Statuses: &statuses, MinPoint: &points, DueBy: &due}
+
+	d, err := Describe(f, WithDialect(DialectPostgres), WithTimePrecision(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, []ClauseDescription{
+		{
+			Column:   "status",
+			Operator: "in",
+			Values:   []any{"todo", "doing"},
+			Text:     "status is one of todo or doing",
+		},
+		{
+			Column:   "story_points",
+			Operator: "gte",
+			Values:   []any{int64(2)},
+			Text:     "story_points is at least 2",
+		},
+		{
+			Column:   "due_date",
+			Operator: "before",
+			Values:   []any{due.Truncate(time.Second)},
+			Text:     "due_date is before 2023-05-05T12:30:15Z",
+		},
+	}, d)
+	assert.Equal(t, "status is one of todo or doing", d[0].String())
+
+	d, err = Describe(filter{})
+	assert.Nil(t, err)
+	assert.Empty(t, d)
+}
+
+func TestDescribeOpenRange(t *testing.T) {
+	type filter struct {
+		Points *[]*int `filter:"story_points,op=range-open"`
+	}
+
+	low, high := 3, 8
+	for _, tc := range []struct {
+		bounds []*int
+		want   string
+	}{
+		{[]*int{&low, &high}, "story_points is between 3 and 8"},
+		{[]*int{&low, nil}, "story_points is at least 3"},
+		{[]*int{nil, &high}, "story_points is at most 8"},
+		{[]*int{nil, nil}, ""},
+	} {
+		d, err := DescribeText(filter{Points: &tc.bounds})
+		assert.Nil(t, err)
+		assert.Equal(t, tc.want, d)
+	}
+}
+
+func TestDescribeEmptyIn(t *testing.T) {
+	type filter struct {
+		Statuses []string `filter:"status,op=in"`
+		Excluded []string `filter:"status,op=not-in"`
+	}
+
+	f := filter{Statuses: []string{}, Excluded: []string{}}
+
+	d, err := DescribeText(f)
+	assert.Nil(t, err)
+	assert.Equal(t, "status is one of nothing and status is none of NULL, which matches nothing", d)
+
+	d, err = DescribeText(f, WithEmptyInBehavior(EmptyInLogical))
+	assert.Nil(t, err)
+	assert.Equal(t, "status is one of nothing and status is none of nothing", d)
+}
+
+func TestDescribeKeepsArgs(t *testing.T) {
+	type filter struct {
+		Expr *Raw `filter:"created_at,op=raw"`
+	}
+
+	created := time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)
+	raw := &Raw{SQL: "created_at > ?", Args: []any{created}}
+
+	// the args of a Raw value are bound as is, the time format doesn't apply
+	d, err := Describe(filter{Expr: raw}, WithTimeFormat("2006-01-02"))
+	assert.Nil(t, err)
+	assert.Equal(t, []any{created}, d[0].Values)
+	assert.Equal(t, []any{created}, raw.Args)
+}
+
+func TestDescribeCustomOperator(t *testing.T) {
+	Operators["custom"] = SimpleOperator("~ ?")
+	defer delete(Operators, "custom")
+
+	type filter struct {
+		Name string `filter:"name,op=custom"`
+	}
+
+	d, err := DescribeText(filter{Name: "bob"})
+	assert.Nil(t, err)
+	assert.Equal(t, "name custom bob", d)
+}
+
+func TestDescribeError(t *testing.T) {
+	type filter struct {
+		Name string `filter:"name,op=in"`
+	}
+
+	_, err := DescribeText(filter{Name: "bob"})
+	assert.ErrorContains(t, err, "field Name (column name)")
+}
@@ -0,0 +1,94 @@
+package queryfilter
+
+import "fmt"
+
+// Dialect identifies a SQL dialect. Selecting a dialect configures the placeholder strategy
+// commonly used by the dialect and makes ToSQL prefer the operator variants registered for
+// the dialect (eg: a different spelling of the same operation) over the global operators.
+type Dialect string
+
+const (
+	DialectPostgres  Dialect = "postgres"
+	DialectMySQL     Dialect = "mysql"
+	DialectSQLite    Dialect = "sqlite"
+	DialectSQLServer Dialect = "sqlserver"
+)
+
+var (
+	// dialects holds the built in dialects and the placeholder strategy they use.
+	dialects = map[Dialect]PlaceholderStrategy{
+		DialectPostgres:  PlaceholderStrategyDollar,
+		DialectMySQL:     PlaceholderStrategyQuestionmark,
+		DialectSQLite:    PlaceholderStrategyQuestionmark,
+		DialectSQLServer: PlaceholderStrategyAt,
+	}
+
+	// dialectOperators holds the operator variants registered per dialect.
+	dialectOperators = map[Dialect]map[string]Operator{}
+)
+
+// Dialects returns the built in dialects.
+func Dialects() []Dialect {
+	return []Dialect{
+		DialectPostgres,
+		DialectMySQL,
+		DialectSQLite,
+		DialectSQLServer,
+	}
+}
+
+// RegisterDialectOperator registers a variant of an operator to be used when the given dialect is selected.
+// When no variant is registered for the dialect, the operator registered through RegisterOperator is used.
+//
+// Note that calling this function multiple times with the same dialect and name will
+// overwrite the function previously registered without warning.
+//
+// Example:
+//
+//	RegisterOperator("regexp", SimpleOperator("REGEXP ?"))
+//	RegisterDialectOperator(DialectPostgres, "regexp", SimpleOperator("~ ?"))
+func RegisterDialectOperator(d Dialect, name string, op Operator) {
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+
+	if dialectOperators[d] == nil {
+		dialectOperators[d] = map[string]Operator{}
+	}
+
+	dialectOperators[d][name] = op
+}
+
+// WithDialect selects the dialect to generate the query for,
+// configuring the placeholder strategy used by the dialect.
+func WithDialect(d Dialect) OptFn {
+	return func(o *Opts) {
+		o.Dialect = d
+		if strategy, ok := dialects[d]; ok {
+			o.PlaceholderStrategy = strategy
+		}
+	}
+}
+
+// DialectResult holds the query and args that a filter results in for a single dialect.
+type DialectResult struct {
+	Query string
+	Args  []any
+}
+
+// RenderForAllDialects renders the filter for each of the built in dialects, which is useful for
+// verifying (eg: snapshot testing) a filter works across databases in one go.
+func RenderForAllDialects(f any, fns ...OptFn) (map[Dialect]DialectResult, error) {
+	results := make(map[Dialect]DialectResult, len(dialects))
+
+	for _, d := range Dialects() {
+		// copy the options, so appending doesn't write into the backing array of the caller
+		query, args, err := ToSQL(f, append(append([]OptFn{}, fns...), WithDialect(d))...)
+		if err != nil {
+			return nil, fmt.Errorf("dialect %s: %w", d, err)
+		}
+
+		results[d] = DialectResult{Query: query, Args: args}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,73 @@
+package queryfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderForAllDialects(t *testing.T) {
+	type filter struct {
+		Name   string `filter:"name"`
+		MinAge int    `filter:"age,op=gt"`
+	}
+
+	results, err := RenderForAllDialects(filter{Name: "bobby", MinAge: 42})
+	assert.Nil(t, err)
+	assert.Len(t, results, len(Dialects()))
+
+	expected := map[Dialect]string{
+		DialectPostgres:  "name = $1 AND age > $2",
+		DialectMySQL:     "name = ? AND age > ?",
+		DialectSQLite:    "name = ? AND age > ?",
+		DialectSQLServer: "name = @p1 AND age > @p2",
+	}
+
+	for d, q := range expected {
+		assert.Equal(t, q, results[d].Query, "dialect %s", d)
+		assert.Equal(t, []any{"bobby", int64(42)}, results[d].Args, "dialect %s", d)
+	}
+}
+
+func TestRenderForAllDialectsKeepsOptions(t *testing.T) {
+	type filter struct {
+		Name string `filter:"name"`
+	}
+
+	// spare capacity, which appending the dialect option would otherwise write into
+	fns := make([]OptFn, 1, 2)
+	fns[0] = WithChainingStrategy(ChainingStrategyOr)
+	marker := WithTagName("marker")
+	fns = append(fns, marker)[:1]
+
+	_, err := RenderForAllDialects(filter{Name: "bobby"}, fns...)
+	assert.Nil(t, err)
+
+	opts := DefaultOpts()
+	fns[:2][1](opts)
+	assert.Equal(t, "marker", opts.TagName)
+}
+
+func TestDialectOperator(t *testing.T) {
+	defer restoreRegistries(snapshotRegistries())
+
+	RegisterOperator("dialect-test", SimpleOperator("LIKE ?"))
+	RegisterDialectOperator(DialectPostgres, "dialect-test", SimpleOperator("ILIKE ?"))
+
+	type filter struct {
+		Name string `filter:"name,op=dialect-test"`
+	}
+
+	q, _, err := ToSQL(filter{Name: "bob%"}, WithDialect(DialectPostgres))
+	assert.Nil(t, err)
+	assert.Equal(t, "name ILIKE $1", q)
+
+	q, _, err = ToSQL(filter{Name: "bob%"}, WithDialect(DialectMySQL))
+	assert.Nil(t, err)
+	assert.Equal(t, "name LIKE ?", q)
+}
+
+func TestRenderForAllDialectsError(t *testing.T) {
+	_, err := RenderForAllDialects("not a struct")
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
@@ -0,0 +1,51 @@
+package queryfilter
+
+import "errors"
+
+// The errors below are wrapped by the errors returned from ToSQL,
+// so they can be checked for using errors.Is, eg:
+//
+//	_, _, err := ToSQL(filter)
+//	if errors.Is(err, queryfilter.ErrUnknownOperator) {...}
+var (
+	// ErrNotStruct is returned when the provided filter is not a struct.
+	ErrNotStruct = errors.New("provided value is not a struct")
+
+	// ErrUnknownOperator is returned when a tag references an operator that isn't registered.
+	ErrUnknownOperator = errors.New("operator is not available")
+
+	// ErrUnsupportedType is returned when a field holds a value of a type that can't be used in a query.
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrTypeMismatch is returned when a field holds a value of a type (or shape) its operator doesn't work on,
+	// eg: a string for the in operator, or a slice of a single element for between.
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrInvalidChainingStrategy is returned when the chaining strategy is neither AND nor OR.
+	ErrInvalidChainingStrategy = errors.New("invalid chaining strategy")
+
+	// ErrInvalidPlaceholderOffset is returned when numbered placeholders would start below 1, eg: `$0`.
+	ErrInvalidPlaceholderOffset = errors.New("invalid placeholder offset")
+
+	// ErrInvalidTag is returned when a filter tag can't be parsed.
+	ErrInvalidTag = errors.New("incorrectly formatted tag")
+
+	// ErrColumnNotAllowed is returned when a clause references a column outside of the allowed columns.
+	ErrColumnNotAllowed = errors.New("column is not allowed")
+
+	// ErrInvalidColumn is returned when a column name isn't a valid identifier.
+	ErrInvalidColumn = errors.New("invalid column name")
+
+	// ErrTooManyElements is returned when a slice holds more elements than allowed, see WithMaxInElements.
+	ErrTooManyElements = errors.New("too many elements")
+
+	// ErrUnknownOverride is returned when an override references a column none of the fields filter on.
+	ErrUnknownOverride = errors.New("override doesn't match a column")
+
+	// ErrPlaceholderMismatch is returned when the number of placeholders in the query doesn't match the number of
+	// values, which named placeholders can't be derived for (eg: a Raw value with more placeholders than args).
+	ErrPlaceholderMismatch = errors.New("placeholders don't match the values")
+
+	// ErrUnexportedField is returned when an unexported field carries a filter tag, as its value can't be read.
+	ErrUnexportedField = errors.New("unexported field can't be filtered on")
+)
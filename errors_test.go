@@ -0,0 +1,47 @@
+package queryfilter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	type unknownOperator struct {
+		Name string `filter:"name,op=unknown"`
+	}
+
+	type unsupportedType struct {
+		Data chan string `filter:"data"`
+	}
+
+	type invalidTag struct {
+		Name string `filter:"name,unknown"`
+	}
+
+	type unexportedField struct {
+		name string `filter:"name"`
+	}
+
+	type typeMismatch struct {
+		Name string `filter:"name,op=in"`
+	}
+
+	cases := []struct {
+		filter any
+		err    error
+	}{
+		{filter: "not a struct", err: ErrNotStruct},
+		{filter: unknownOperator{Name: "bobby"}, err: ErrUnknownOperator},
+		{filter: unsupportedType{Data: make(chan string)}, err: ErrUnsupportedType},
+		{filter: invalidTag{Name: "bobby"}, err: ErrInvalidTag},
+		{filter: unexportedField{name: "bobby"}, err: ErrUnexportedField},
+		{filter: typeMismatch{Name: "bobby"}, err: ErrTypeMismatch},
+	}
+
+	for _, tc := range cases {
+		_, _, err := ToSQL(tc.filter)
+		assert.True(t, errors.Is(err, tc.err), "expected %v to wrap %v", err, tc.err)
+	}
+}
@@ -38,7 +38,7 @@ type Filters struct {
 	Status    *[]string  `filter:"status,op=in"`
 	MinPoints *int       `filter:"story_points,op=gte"`
 	MaxPoints *int       `filter:"story_points,op=lte"`
-	DueBefore *time.Time `filter:"due_date,op=gt"`
+	DueBefore *time.Time `filter:"due_date,op=before"`
 }
 
 type Task struct {
@@ -74,12 +74,12 @@ func main() {
 	//
 	// optionally you can set the PlaceholderOffset to start at a different number using:
 	// qf.WithPlaceholderStrategy(qf.PlaceholderStrategyDollar, qf.PlaceholderOffset(10))
-	query, vars, err := qf.ToSQL(filter, qf.WithPlaceholderStrategy(qf.PlaceholderStrategyDollar))
+	where, vars, err := qf.WhereClause(filter, qf.WithPlaceholderStrategy(qf.PlaceholderStrategyDollar))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	query = fmt.Sprintf("SELECT * FROM tasks WHERE %s LIMIT 1", query)
+	query := fmt.Sprintf("SELECT * FROM tasks %s LIMIT 1", where)
 	fmt.Printf("query: %s\n", query)
 	fmt.Printf("vars: %v\n", vars)
 
@@ -37,7 +37,7 @@ type Filters struct {
 	Status    *[]string  `filter:"status,op=in"`
 	MinPoints *int       `filter:"story_points,op=gte"`
 	MaxPoints *int       `filter:"story_points,op=lte"`
-	DueBefore *time.Time `filter:"due_date,op=gt"`
+	DueBefore *time.Time `filter:"due_date,op=before"`
 }
 
 type Task struct {
@@ -66,12 +66,12 @@ func main() {
 		MaxPoints: &maxPoints,
 	}
 
-	query, vars, err := qf.ToSQL(filter)
+	where, vars, err := qf.WhereClause(filter)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	query = fmt.Sprintf("SELECT * FROM tasks WHERE %s LIMIT 1", query)
+	query := fmt.Sprintf("SELECT * FROM tasks %s LIMIT 1", where)
 	fmt.Printf("query: %s\n", query)
 	fmt.Printf("vars: %v\n", vars)
 
@@ -0,0 +1,85 @@
+package queryfilter
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interpolate substitutes each placeholder (?) of the query with its arg written as an SQL literal, eg:
+// `name = ? AND age > ?` with "jane" and 18 results in `name = 'jane' AND age > 18`.
+// This is meant for debugging only, eg: logging a query that can be copied into a database console.
+//
+// The query is expected to use questionmarks (PlaceholderStrategyQuestionmark), as returned by ToSQL by default.
+// Escaped questionmarks (??) are written as a single `?` rather than substituted, see PlaceholderList.
+// Strings are single quoted (doubling any single quotes), numbers are written as is, booleans as TRUE or FALSE,
+// times as a quoted timestamp and nil as NULL. Values implementing driver.Valuer are written as the value they
+// return. An error is returned when the number of args doesn't match the number of placeholders, or for args
+// that can't be written as a literal.
+//
+// Note that the result is not safe to execute: the escaping doesn't account for the specifics of each database,
+// so never run an interpolated query, instead pass the args to the database separately.
+func Interpolate(query string, args []any) (string, error) {
+	if n := CountPlaceholders(query); n != len(args) {
+		return "", fmt.Errorf("query holds %d placeholders; got %d args", n, len(args))
+	}
+
+	literals := make([]string, len(args))
+	for i, arg := range args {
+		literal, err := sqlLiteral(arg)
+		if err != nil {
+			return "", fmt.Errorf("arg %d: %w", i, err)
+		}
+
+		literals[i] = literal
+	}
+
+	// replace the placeholders the same way the placeholder strategies do, resolving the escaped questionmarks
+	return replace(query, 0, func(b *strings.Builder, n int) {
+		b.WriteString(literals[n])
+	}), nil
+}
+
+// sqlLiteral writes the value as an SQL literal, see Interpolate.
+func sqlLiteral(v any) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		v = value
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteLiteral(v), nil
+	case []byte:
+		return quoteLiteral(string(v)), nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case time.Time:
+		return quoteLiteral(v.Format("2006-01-02 15:04:05.999999999Z07:00")), nil
+	case int, int8, int16, int32, uint, uint8, uint16, uint32, float32:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("%w: can't write %T as a literal", ErrUnsupportedType, v)
+	}
+}
+
+// quoteLiteral single quotes the string, escaping single quotes by doubling them.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
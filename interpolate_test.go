@@ -0,0 +1,48 @@
+package queryfilter
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	type filter struct {
+		Name     *string    `filter:"name"`
+		MinAge   *int       `filter:"age,op=gt"`
+		Ratio    *float64   `filter:"ratio,op=lt"`
+		Active   *bool      `filter:"active"`
+		Statuses []string   `filter:"status,op=in"`
+		DueBy    *time.Time `filter:"due,op=before"`
+	}
+
+	name, age, ratio, active := "o'brien", 18, 0.5, true
+	due := time.Date(2023, 5, 5, 12, 30, 0, 0, time.UTC)
+	f := filter{
+		Name: &name, MinAge: &age, Ratio: &ratio, Active: &active,
+		Statuses: []string{"todo", "doing"}, DueBy: &due,
+	}
+
+	q, args, err := ToSQL(f)
+	assert.Nil(t, err)
+
+	s, err := Interpolate(q, args)
+	assert.Nil(t, err)
+	assert.Equal(t, "name = 'o''brien' AND age > 18 AND ratio < 0.5 AND active = TRUE AND "+
+		"status IN('todo','doing') AND due < '2023-05-05 12:30:00Z'", s)
+
+	s, err = Interpolate("deleted_at = ? AND note = ?", []any{nil, sql.NullString{String: "x", Valid: true}})
+	assert.Nil(t, err)
+	assert.Equal(t, "deleted_at = NULL AND note = 'x'", s)
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	_, err := Interpolate("name = ? AND age > ?", []any{"jane"})
+	assert.ErrorContains(t, err, "query holds 2 placeholders; got 1 args")
+
+	_, err = Interpolate("tags = ?", []any{[]string{"a"}})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	assert.ErrorContains(t, err, "arg 0: unsupported type: can't write []string as a literal")
+}
@@ -0,0 +1,109 @@
+package queryfilter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ToSQLFromMap works like ToSQL, but takes the filter as a map rather than a struct,
+// for filters of which the shape isn't known at compile time. Each key is used as the column
+// and each value is compared using the default operator (`eq`, unless configured otherwise), eg:
+//
+//	ToSQLFromMap(map[string]any{"status": "todo", "color": "red"})
+//
+// results in `color = ? AND status = ?`. To use a different operator, pass a Clause as the value
+// with its Op and Val set (the default operator is used when Op is empty):
+//
+//	ToSQLFromMap(map[string]any{"color": Clause{Op: "in", Val: []string{"red", "blue"}}})
+//
+// The values are read the same way as the fields of a filter struct. The clauses are ordered by column.
+//
+// As the keys are typically runtime input (eg: the query parameters of a request) and are written into
+// the query as the column, each key must be a plain (optionally qualified) identifier, eg: `status` or
+// `tasks.status`. An error wrapping ErrInvalidColumn is returned otherwise, so a key like `id; DROP TABLE`
+// never ends up in the query. Use WithAllowedColumns to restrict which columns can be filtered on at all.
+func ToSQLFromMap(m map[string]any, fns ...OptFn) (string, []any, error) {
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	clauses, err := buildMapClauses(m, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sql, args, err := toSQL(clauses, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := completeQuery(sql, opts)
+	observe(query, args, opts)
+
+	return query, args, nil
+}
+
+func buildMapClauses(m map[string]any, opts *Opts) ([]Clause, error) {
+	if err := validateOpts(opts); err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(m))
+	for col := range m {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	var (
+		clauses    = make([]Clause, 0, len(m))
+		overridden = map[string]bool{}
+	)
+
+	for _, col := range columns {
+		// the keys are written into the query, so they're checked regardless of StrictColumns
+		if !identifierPattern.MatchString(col) {
+			return nil, fmt.Errorf("%w: key %q", ErrInvalidColumn, col)
+		}
+
+		clause := Clause{Field: col, Col: col, Op: opts.DefaultOperator, nilIsNull: opts.NilAsIsNull, opts: opts}
+		if opts.ColumnMapper != nil {
+			clause.Col = opts.ColumnMapper(col, col)
+		}
+
+		val := m[col]
+		// a Clause without an operation uses the default operator
+		if c, ok := val.(Clause); ok {
+			if c.Op != "" {
+				clause.Op = c.Op
+			}
+			val = c.Val
+		}
+
+		// a nil override skips the key, like it skips the field of a filter struct
+		if override, ok := opts.Overrides[clause.Col]; ok {
+			overridden[clause.Col] = true
+			if override == nil {
+				continue
+			}
+			val = override
+		}
+
+		if opts.StrictColumns && !identifierPattern.MatchString(clause.Col) {
+			return nil, fmt.Errorf("key %s: %w: %q", col, ErrInvalidColumn, clause.Col)
+		}
+
+		if err := clause.readValue(reflect.ValueOf(val)); err != nil {
+			return nil, err
+		}
+
+		clauses = append(clauses, clause)
+	}
+
+	if err := checkOverrides(opts, overridden); err != nil {
+		return nil, err
+	}
+
+	return clauses, nil
+}
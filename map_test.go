@@ -0,0 +1,97 @@
+package queryfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSQLFromMap(t *testing.T) {
+	name := "bobby"
+	m := map[string]any{
+		"status": "todo",
+		"name":   &name,
+		"age":    Clause{Op: "gt", Val: 42},
+		"color":  Clause{Op: "in", Val: []string{"red", "blue"}},
+		"unset":  nil,
+	}
+
+	q, v, e := ToSQLFromMap(m, WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(t, "age > $1 AND color IN($2,$3) AND name = $4 AND status = $5", q)
+	assert.Equal(t, []any{int64(42), "red", "blue", "bobby", "todo"}, v)
+}
+
+func TestToSQLFromMapWithNilAsIsNull(t *testing.T) {
+	m := map[string]any{
+		"parent_id":   nil,
+		"archived_at": Clause{Op: "neq", Val: nil},
+		"age":         Clause{Op: "gt", Val: nil},
+	}
+
+	q, v, e := ToSQLFromMap(m, WithNilAsIsNull(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "archived_at IS NOT NULL AND parent_id IS NULL", q)
+	assert.Empty(t, v)
+
+	q, _, e = ToSQLFromMap(m)
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+}
+
+func TestToSQLFromMapWithOverride(t *testing.T) {
+	m := map[string]any{
+		"tenant_id": 1,
+		"status":    Clause{Op: "neq", Val: "todo"},
+		"name":      "bobby",
+	}
+
+	q, v, e := ToSQLFromMap(m, WithOverride("tenant_id", 7), WithOverride("status", "done"), WithOverride("name", nil))
+	assert.Nil(t, e)
+	assert.Equal(t, "status <> ? AND tenant_id = ?", q)
+	assert.Equal(t, []any{"done", int64(7)}, v)
+
+	_, _, e = ToSQLFromMap(m, WithOverride("tenant", 7))
+	assert.ErrorIs(t, e, ErrUnknownOverride)
+}
+
+func TestToSQLFromMapClauseWithoutOperator(t *testing.T) {
+	q, v, e := ToSQLFromMap(map[string]any{"a": Clause{Val: 1}})
+	assert.Nil(t, e)
+	assert.Equal(t, "a = ?", q)
+	assert.Equal(t, []any{int64(1)}, v)
+
+	q, _, e = ToSQLFromMap(map[string]any{"a": Clause{Val: 1}}, WithDefaultOperator("gt"))
+	assert.Nil(t, e)
+	assert.Equal(t, "a > ?", q)
+}
+
+func TestToSQLFromMapErrors(t *testing.T) {
+	_, _, e := ToSQLFromMap(map[string]any{"name": Clause{Op: "unknown", Val: 1}})
+	assert.ErrorIs(t, e, ErrUnknownOperator)
+
+	_, _, e = ToSQLFromMap(map[string]any{"name": make(chan int)})
+	assert.ErrorIs(t, e, ErrUnsupportedType)
+
+	_, _, e = ToSQLFromMap(map[string]any{"name; DROP TABLE users": 1}, WithStrictColumns(true))
+	assert.ErrorIs(t, e, ErrInvalidColumn)
+
+	// keys are checked by default, as they're written into the query
+	_, _, e = ToSQLFromMap(map[string]any{"evil; DROP": "x"})
+	assert.ErrorIs(t, e, ErrInvalidColumn)
+	assert.ErrorContains(t, e, `invalid column name: key "evil; DROP"`)
+
+	q, _, e := ToSQLFromMap(map[string]any{"tasks.status": "todo"})
+	assert.Nil(t, e)
+	assert.Equal(t, "tasks.status = ?", q)
+}
+
+func TestToSQLFromMapWithColumnMapper(t *testing.T) {
+	mapper := func(_, column string) string {
+		return "tasks." + column
+	}
+
+	q, _, e := ToSQLFromMap(map[string]any{"status": "todo"}, WithColumnMapper(mapper))
+	assert.Nil(t, e)
+	assert.Equal(t, "tasks.status = ?", q)
+}
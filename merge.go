@@ -0,0 +1,84 @@
+package queryfilter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToSQLMerged takes multiple filter structs and merges their resulting clauses into a single
+// parameterized SQL string, joining the filters using the given chaining strategy. The clauses
+// within each filter are joined using the chaining strategy configured through the options.
+//
+// Filters that don't result in any clauses are dropped, so they don't introduce stray
+// `()` or `OR` statements. When more than one filter remains, each of them is wrapped
+// in parentheses to preserve precedence, eg:
+//
+//	(name = ? AND age > ?) OR (status = ?)
+//
+// Placeholders are applied once on the merged query, so positional placeholders are numbered
+// consistently across all filters.
+func ToSQLMerged(strategy ChainingStrategy, filters []any, fns ...OptFn) (string, []any, error) {
+	if !strategy.valid() {
+		return "", nil, fmt.Errorf("%w: %q", ErrInvalidChainingStrategy, strategy)
+	}
+
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	var (
+		groups []string
+		args   []any
+
+		// the overrides are checked across all filters, as any of them may filter on the column
+		overridden = map[string]bool{}
+	)
+
+	for _, f := range filters {
+		clauses, err := collectClauses(f, opts, overridden)
+		if err != nil {
+			return "", nil, err
+		}
+
+		sql, newArgs, err := toSQL(clauses, opts)
+		if err != nil {
+			return "", nil, err
+		}
+
+		// drop empty groups
+		if sql == "" {
+			continue
+		}
+
+		groups = append(groups, sql)
+		args = append(args, newArgs...)
+	}
+
+	if err := checkOverrides(opts, overridden); err != nil {
+		return "", nil, err
+	}
+
+	if len(groups) > 1 {
+		for i, g := range groups {
+			groups[i] = fmt.Sprintf("(%s)", g)
+		}
+	}
+
+	sep := fmt.Sprintf(" %s ", strategy)
+	query := completeQuery(strings.Join(groups, sep), opts)
+	observe(query, args, opts)
+
+	return query, args, nil
+}
+
+// Combine combines the clauses of multiple filter structs, joining them using the given chaining strategy
+// and the default options, eg:
+//
+//	query, args, err := queryfilter.Combine(queryfilter.ChainingStrategyOr, userFilter, dateFilter)
+//
+// This allows composing modular filter structs rather than a single large one. See ToSQLMerged,
+// which Combine is a shorthand for, to configure the options.
+func Combine(strategy ChainingStrategy, filters ...any) (string, []any, error) {
+	return ToSQLMerged(strategy, filters)
+}
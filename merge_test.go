@@ -0,0 +1,105 @@
+package queryfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mergeUserFilter struct {
+	Name   *string `filter:"name"`
+	MinAge *int    `filter:"age,op=gt"`
+}
+
+type mergeStatusFilter struct {
+	Status *string `filter:"status"`
+}
+
+func TestToSQLMerged(t *testing.T) {
+	name, minAge, status := "bobby", 42, "todo"
+
+	q, v, e := ToSQLMerged(
+		ChainingStrategyOr,
+		[]any{
+			mergeUserFilter{Name: &name, MinAge: &minAge},
+			mergeStatusFilter{Status: &status},
+		},
+		WithPlaceholderStrategy(PlaceholderStrategyDollar),
+	)
+
+	assert.Nil(t, e)
+	assert.Equal(t, "(name = $1 AND age > $2) OR (status = $3)", q)
+	assert.Equal(t, []any{"bobby", int64(42), "todo"}, v)
+}
+
+func TestToSQLMergedDropsEmptyFilters(t *testing.T) {
+	name := "bobby"
+
+	q, v, e := ToSQLMerged(
+		ChainingStrategyOr,
+		[]any{
+			mergeStatusFilter{},
+			mergeUserFilter{Name: &name},
+			mergeStatusFilter{},
+		},
+	)
+
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+
+	q, v, e = ToSQLMerged(ChainingStrategyOr, []any{mergeStatusFilter{}, mergeUserFilter{}})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+}
+
+func TestToSQLMergedError(t *testing.T) {
+	_, _, e := ToSQLMerged(ChainingStrategyAnd, []any{mergeStatusFilter{}, "not a struct"})
+	assert.ErrorIs(t, e, ErrNotStruct)
+
+	_, _, e = ToSQLMerged("XOR", []any{mergeStatusFilter{}})
+	assert.ErrorIs(t, e, ErrInvalidChainingStrategy)
+}
+
+func TestToSQLMergedWithOverride(t *testing.T) {
+	name, status := "bobby", "todo"
+	filters := []any{mergeUserFilter{Name: &name}, mergeStatusFilter{Status: &status}}
+
+	q, v, e := ToSQLMerged(ChainingStrategyAnd, filters, WithOverride("status", "done"))
+	assert.Nil(t, e)
+	assert.Equal(t, "(name = ?) AND (status = ?)", q)
+	assert.Equal(t, []any{"bobby", "done"}, v)
+
+	_, _, e = ToSQLMerged(ChainingStrategyAnd, filters, WithOverride("tenant_id", 7))
+	assert.ErrorIs(t, e, ErrUnknownOverride)
+}
+
+func TestToSQLMergedDefaultPredicate(t *testing.T) {
+	q, v, e := ToSQLMerged(
+		ChainingStrategyOr,
+		[]any{mergeStatusFilter{}, mergeUserFilter{}},
+		WithDefaultPredicate("1=1"),
+	)
+
+	assert.Nil(t, e)
+	assert.Equal(t, "1=1", q)
+	assert.Empty(t, v)
+}
+
+func TestCombine(t *testing.T) {
+	name, minAge, status := "bobby", 42, "todo"
+
+	q, v, e := Combine(ChainingStrategyOr, mergeUserFilter{Name: &name, MinAge: &minAge}, mergeStatusFilter{Status: &status})
+	assert.Nil(t, e)
+	assert.Equal(t, "(name = ? AND age > ?) OR (status = ?)", q)
+	assert.Equal(t, []any{"bobby", int64(42), "todo"}, v)
+
+	q, v, e = Combine(ChainingStrategyAnd, mergeStatusFilter{}, mergeUserFilter{Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+
+	_, _, e = Combine(ChainingStrategyAnd, "not a struct")
+	assert.ErrorIs(t, e, ErrNotStruct)
+}
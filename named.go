@@ -0,0 +1,144 @@
+package queryfilter
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// nonIdentifierChars matches the characters that aren't allowed in parameter names.
+var nonIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// ToSQLNamed takes a filter struct and returns a SQL string with named placeholders (eg: `:age`)
+// along with a map holding the values by name. This is compatible with named queries
+// in github.com/jmoiron/sqlx, eg:
+//
+//	query, args, err := ToSQLNamed(filter)
+//	rows, err := db.NamedQuery("SELECT * FROM tasks WHERE "+query, args)
+//
+// The names are derived from the columns of the clauses, replacing any character that's not allowed
+// in a name by an underscore (eg: `tasks.status` becomes `tasks_status`). When a name is used more than
+// once (eg: a column used by multiple fields, or the elements of an `in` operation) a numeric suffix is
+// added to the subsequent names, like `:story_points`, `:story_points_2`. The values of groups (see the
+// group flag and the groups operator) are named after the columns of the clauses within the group.
+//
+// The placeholder strategy and offset options don't apply to named placeholders, the other options
+// (eg: WithOuterParentheses and WithDefaultPredicate) apply like they do for ToSQL. An error wrapping
+// ErrPlaceholderMismatch is returned when the number of placeholders doesn't match the number of values,
+// eg: a Raw value with more placeholders than args, as the names can't be matched to the placeholders.
+func ToSQLNamed(f any, fns ...OptFn) (string, map[string]any, error) {
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	sql, names, args, err := toSQLNamed(f, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	named := make(map[string]any, len(args))
+	for i, name := range names {
+		named[name] = args[i]
+	}
+
+	replacer := func(b *strings.Builder, i int) {
+		b.WriteString(":")
+		b.WriteString(names[i])
+	}
+
+	query := replace(sql, 0, replacer)
+	observe(query, args, opts)
+
+	return query, named, nil
+}
+
+// ToSQLNamedArgs takes a filter struct and returns a SQL string with named placeholders (eg: `@age`)
+// along with the values as sql.NamedArg, for drivers supporting named parameters through database/sql, eg:
+//
+//	query, args, err := ToSQLNamedArgs(filter)
+//	rows, err := db.Query("SELECT * FROM tasks WHERE "+query, args...)
+//
+// The names are derived from the columns like ToSQLNamed does, including the numeric suffix added to
+// names that are used more than once (eg: `@story_points`, `@story_points_2`).
+//
+// Placeholders are prefixed using an at sign, as used by SQL Server, or using a colon (eg: `:age`)
+// when the placeholder strategy is set to PlaceholderStrategyColon.
+func ToSQLNamedArgs(f any, fns ...OptFn) (string, []any, error) {
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	query, names, args, err := toSQLNamed(f, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	prefix := "@"
+	if opts.PlaceholderStrategy == PlaceholderStrategyColon {
+		prefix = ":"
+	}
+
+	namedArgs := make([]any, len(args))
+	for i, name := range names {
+		namedArgs[i] = sql.Named(name, args[i])
+	}
+
+	replacer := func(b *strings.Builder, i int) {
+		b.WriteString(prefix)
+		b.WriteString(names[i])
+	}
+
+	query = replace(query, 0, replacer)
+	observe(query, namedArgs, opts)
+
+	return query, namedArgs, nil
+}
+
+// toSQLNamed builds the query with questionmark placeholders and returns it along with the
+// unique name for each of the args, in the order of the placeholders.
+func toSQLNamed(f any, opts *Opts) (string, []string, []any, error) {
+	// the query is built using questionmarks, so the placeholder offset (which named placeholders
+	// don't use) isn't validated
+	questionmarks := *opts
+	questionmarks.PlaceholderStrategy = PlaceholderStrategyQuestionmark
+	opts = &questionmarks
+
+	clauses, err := buildClauses(f, opts)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	segs, err := renderSegments(clauses, opts)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var (
+		names []string
+		taken = map[string]bool{}
+	)
+
+	// the values of groups are named after the columns of the clauses within the group
+	for _, col := range segmentColumns(segs) {
+		base := nonIdentifierChars.ReplaceAllString(col, "_")
+		name := base
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+
+		taken[name] = true
+		names = append(names, name)
+	}
+
+	// the names are matched to the placeholders by position, so each placeholder needs a value
+	sql, args := joinSegments(segs, opts)
+	sql = wrapQuery(sql, opts)
+	if n := CountPlaceholders(sql); n != len(names) {
+		return "", nil, nil, fmt.Errorf("%w: %d placeholders for %d values", ErrPlaceholderMismatch, n, len(names))
+	}
+
+	return sql, names, args, nil
+}
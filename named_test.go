@@ -0,0 +1,184 @@
+package queryfilter
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSQLNamed(t *testing.T) {
+	type filter struct {
+		Status    []string `filter:"tasks.status,op=in"`
+		MinPoints int      `filter:"story_points,op=gte"`
+		MaxPoints int      `filter:"story_points,op=lte"`
+	}
+
+	f := filter{
+		Status:    []string{"todo", "doing"},
+		MinPoints: 2,
+		MaxPoints: 5,
+	}
+
+	q, v, e := ToSQLNamed(f, WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(
+		t,
+		"tasks.status IN(:tasks_status,:tasks_status_2) AND story_points >= :story_points AND story_points <= :story_points_2",
+		q,
+	)
+	assert.Equal(t, map[string]any{
+		"tasks_status":   "todo",
+		"tasks_status_2": "doing",
+		"story_points":   int64(2),
+		"story_points_2": int64(5),
+	}, v)
+}
+
+func TestToSQLNamedEmpty(t *testing.T) {
+	type filter struct {
+		Name *string `filter:"name"`
+	}
+
+	q, v, e := ToSQLNamed(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+
+	_, _, e = ToSQLNamed("not a struct")
+	assert.ErrorIs(t, e, ErrNotStruct)
+}
+
+func TestToSQLNamedArgs(t *testing.T) {
+	type filter struct {
+		Status    []string `filter:"tasks.status,op=in"`
+		MinPoints int      `filter:"story_points,op=gte"`
+		MaxPoints int      `filter:"story_points,op=lte"`
+	}
+
+	f := filter{
+		Status:    []string{"todo", "doing"},
+		MinPoints: 2,
+		MaxPoints: 5,
+	}
+
+	q, v, e := ToSQLNamedArgs(f)
+	assert.Nil(t, e)
+	assert.Equal(
+		t,
+		"tasks.status IN(@tasks_status,@tasks_status_2) AND story_points >= @story_points AND story_points <= @story_points_2",
+		q,
+	)
+	assert.Equal(t, []any{
+		sql.Named("tasks_status", "todo"),
+		sql.Named("tasks_status_2", "doing"),
+		sql.Named("story_points", int64(2)),
+		sql.Named("story_points_2", int64(5)),
+	}, v)
+
+	q, _, e = ToSQLNamedArgs(f, WithPlaceholderStrategy(PlaceholderStrategyColon))
+	assert.Nil(t, e)
+	assert.Equal(
+		t,
+		"tasks.status IN(:tasks_status,:tasks_status_2) AND story_points >= :story_points AND story_points <= :story_points_2",
+		q,
+	)
+
+	_, _, e = ToSQLNamedArgs("not a struct")
+	assert.ErrorIs(t, e, ErrNotStruct)
+}
+
+type namedCondition struct {
+	Status *string `filter:"status"`
+	Points *int    `filter:"story_points,op=gte"`
+}
+
+type namedGroupFilter struct {
+	Group      namedCondition   `filter:",group"`
+	Conditions []namedCondition `filter:"conditions,op=groups"`
+}
+
+func TestToSQLNamedWithGroups(t *testing.T) {
+	status, points := "todo", 3
+	f := namedGroupFilter{
+		Group:      namedCondition{Status: &status},
+		Conditions: []namedCondition{{Status: &status, Points: &points}},
+	}
+
+	q, v, e := ToSQLNamed(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "(status = :status) AND ((status = :status_2 AND story_points >= :story_points))", q)
+	assert.Equal(t, map[string]any{
+		"status":       "todo",
+		"status_2":     "todo",
+		"story_points": int64(3),
+	}, v)
+}
+
+func TestToSQLNamedArgsWithGroups(t *testing.T) {
+	status, points := "todo", 3
+	f := namedGroupFilter{
+		Group:      namedCondition{Status: &status},
+		Conditions: []namedCondition{{Status: &status, Points: &points}},
+	}
+
+	q, v, e := ToSQLNamedArgs(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "(status = @status) AND ((status = @status_2 AND story_points >= @story_points))", q)
+	assert.Equal(t, []any{
+		sql.Named("status", "todo"),
+		sql.Named("status_2", "todo"),
+		sql.Named("story_points", int64(3)),
+	}, v)
+}
+
+func TestToSQLNamedWithOptions(t *testing.T) {
+	type filter struct {
+		Name   *string `filter:"name"`
+		Status *string `filter:"status"`
+	}
+
+	name, status := "bobby", "todo"
+	q, _, e := ToSQLNamed(filter{Name: &name, Status: &status}, WithOuterParentheses(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "(name = :name AND status = :status)", q)
+
+	q, v, e := ToSQLNamedArgs(filter{}, WithDefaultPredicate("1=1"))
+	assert.Nil(t, e)
+	assert.Equal(t, "1=1", q)
+	assert.Empty(t, v)
+}
+
+func TestToSQLNamedIgnoresPlaceholderOffset(t *testing.T) {
+	type filter struct {
+		Name *string `filter:"name"`
+	}
+
+	name := "bobby"
+	q, _, e := ToSQLNamedArgs(filter{Name: &name},
+		WithPlaceholderStrategy(PlaceholderStrategyColon), WithPlaceholderOffset(0))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = :name", q)
+
+	q, _, e = ToSQLNamed(filter{Name: &name},
+		WithPlaceholderStrategy(PlaceholderStrategyDollar), WithPlaceholderOffset(0))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = :name", q)
+}
+
+func TestToSQLNamedPlaceholderMismatch(t *testing.T) {
+	type filter struct {
+		Search Raw `filter:"search,op=raw"`
+	}
+
+	f := filter{Search: Raw{SQL: "name = ? OR email = ?", Args: []any{"bobby"}}}
+
+	_, _, e := ToSQLNamed(f)
+	assert.ErrorIs(t, e, ErrPlaceholderMismatch)
+
+	_, _, e = ToSQLNamedArgs(f)
+	assert.ErrorIs(t, e, ErrPlaceholderMismatch)
+
+	_, _, e = ToSQLNamed(struct{}{}, WithDefaultPredicate("id = ?"))
+	assert.ErrorIs(t, e, ErrPlaceholderMismatch)
+}
@@ -1,12 +1,57 @@
 package queryfilter
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 )
 
+// Subquery holds a query and the values it binds, for use with the exists, gt-all, lt-all and eq-any operators.
+// The query references its values using questionmarks, so the result of ToSQL (using
+// the default PlaceholderStrategyQuestionmark) composes into it, eg:
+//
+//	where, args, err := queryfilter.ToSQL(orderFilter)
+//	f.HasOrders = &queryfilter.Subquery{
+//		Query: "SELECT 1 FROM orders WHERE orders.user_id = users.id AND " + where,
+//		Args:  args,
+//	}
+//
+// The args are bound as is, so the options the query is built with (eg: WithValueTransformer or WithTimeFormat)
+// don't apply to them again.
+//
+// Escaped questionmarks (??) are kept by PlaceholderStrategyQuestionmark, so a literal questionmark in the
+// result (eg: the jsonb `?` operator) stays escaped within the subquery and is written as a single `?`
+// once the placeholders of the outer query are applied. See PlaceholderList.
+type Subquery struct {
+	Query string
+	Args  []any
+}
+
+// Raw holds a query segment and the values it binds, for use with the raw operator. The segment references
+// its values using questionmarks, which are rewritten like the placeholders of any other operator.
+//
+// The column is prepended to the segment (eg: `> ? - 1` results in `age > ? - 1`), unless the segment places the
+// column itself using ColumnToken (eg: `COALESCE({col}, 0) > ?`). Like the args of a Subquery, the args are
+// bound as is.
+type Raw struct {
+	SQL  string
+	Args []any
+}
+
 // Operator is a function that receives a clause and returns the query segment
-// as a string and a slice of values.
+// as a string and a slice of values. Values are referenced in the query segment
+// using questionmarks (?), see PlaceholderList for details on how these are
+// rewritten and how to emit a literal questionmark.
+//
+// The column is prepended to the returned query segment, so an operator returning `> ?`
+// results in `age > ?`. Operators that need the column elsewhere in the segment (eg: within a
+// function call) mark the segment as complete using Clause.FullSegment instead, placing the column using
+// ColumnToken, eg: c.FullSegment("LOWER({col}) = LOWER(?)"). See ColumnOperator for a shorthand.
+// An operator returning an empty full segment, c.FullSegment(""), results in the clause being skipped.
 //
 // Custom operators can be defined by assigning them by name to the global
 // Operators map, eg:
@@ -19,6 +64,47 @@ import (
 //	}
 type Operator func(c Clause) (string, []any, error)
 
+// ContextOperator is an operator that receives the context the query is built with,
+// which is passed using ToSQLContext. It can be registered by converting it to an Operator
+// using its Operator method, eg:
+//
+//	RegisterOperator("tenant", ContextOperator(func(ctx context.Context, c Clause) (string, []any, error) {
+//		return "= ?", []any{ctx.Value(tenantKey)}, nil
+//	}).Operator())
+type ContextOperator func(ctx context.Context, c Clause) (string, []any, error)
+
+// Operator converts the context operator into an Operator, passing it the context of the clause.
+func (op ContextOperator) Operator() Operator {
+	return func(c Clause) (string, []any, error) {
+		return op(c.Context(), c)
+	}
+}
+
+// OptsOperator is an operator that receives a copy of the options the query is built with, for operators
+// that depend on them, eg: an operator emitting several comparisons keeps them together when the clauses
+// are chained using OR (like range does), or an operator adapting its segment to the dialect or time options.
+// It can be registered by converting it to an Operator using its Operator method, eg:
+//
+//	RegisterOperator("day", OptsOperator(func(opts Opts, c Clause) (string, []any, error) {
+//		day := c.Val.(time.Time).Truncate(24 * time.Hour)
+//		segment := "{col} >= ? AND {col} < ?"
+//		if opts.ChainingStrategy == ChainingStrategyOr && !opts.WrapClauses {
+//			segment = "(" + segment + ")"
+//		}
+//		return c.FullSegment(segment), []any{day, day.Add(24 * time.Hour)}, nil
+//	}).Operator())
+//
+// Note that the placeholders of the whole query are replaced once it's rendered, so the operator should keep
+// using questionmarks regardless of the placeholder strategy, as the position of its values isn't known up front.
+type OptsOperator func(opts Opts, c Clause) (string, []any, error)
+
+// Operator converts the options operator into an Operator, passing it a copy of the options of the clause.
+func (op OptsOperator) Operator() Operator {
+	return func(c Clause) (string, []any, error) {
+		return op(*c.options(), c)
+	}
+}
+
 // RegisterOperator registers an operator with the given name and function.
 // the name, given to the operator here, can be used to reference the operator
 // from the struct tag.
@@ -34,72 +120,336 @@ type Operator func(c Clause) (string, []any, error)
 //	    Price int `filter:"price,op=eq"`
 //	                                ^^--- operator name
 //	}
+//
+// RegisterOperator is safe to call concurrently with building queries. Assigning to the
+// Operators map directly is not, and should be limited to initialization (eg: init functions).
 func RegisterOperator(name string, op Operator) {
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+
 	Operators[name] = op
 }
 
+// AliasOperator registers alias as another name for the operator registered as target, including its dialect
+// variants and describer. An error wrapping ErrUnknownOperator is returned when target isn't registered.
+//
+// Aliases are resolved at registration time rather than when building queries: registering the target again
+// later doesn't affect the alias, unless AliasOperator is called again.
+//
+//	queryfilter.AliasOperator("at-least", "gte")
+func AliasOperator(alias, target string) error {
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+
+	op, ok := Operators[target]
+	if !ok {
+		return fmt.Errorf("alias %s: operator %s: %w", alias, target, ErrUnknownOperator)
+	}
+
+	Operators[alias] = op
+	for _, variants := range dialectOperators {
+		if variant, ok := variants[target]; ok {
+			variants[alias] = variant
+		}
+	}
+
+	if describer, ok := registeredDescriber(target); ok {
+		RegisterDescriber(alias, describer)
+	}
+
+	return nil
+}
+
+// SnapshotOperators returns a copy of the globally registered operators, which can be passed
+// to RestoreOperators to undo the registrations made in the meantime. This allows isolating
+// tests that register operators, eg:
+//
+//	func TestMyOperator(t *testing.T) {
+//		defer queryfilter.RestoreOperators(queryfilter.SnapshotOperators())
+//
+//		queryfilter.RegisterOperator("my-operator", ...)
+//	}
+//
+// Note that the operator variants registered per dialect and the describers (eg: copied by AliasOperator)
+// are not included, so tests registering those should remove them themselves.
+func SnapshotOperators() map[string]Operator {
+	operatorsMu.RLock()
+	defer operatorsMu.RUnlock()
+
+	snapshot := make(map[string]Operator, len(Operators))
+	for name, op := range Operators {
+		snapshot[name] = op
+	}
+
+	return snapshot
+}
+
+// RestoreOperators replaces the globally registered operators with the given snapshot,
+// see SnapshotOperators.
+func RestoreOperators(snapshot map[string]Operator) {
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+
+	for name := range Operators {
+		delete(Operators, name)
+	}
+
+	for name, op := range snapshot {
+		Operators[name] = op
+	}
+}
+
+// operatorsMu guards the globally registered (dialect) operators.
+var operatorsMu sync.RWMutex
+
+// registeredOperator returns the operator registered under the given name, preferring
+// the variant registered for the given dialect.
+func registeredOperator(d Dialect, name string) (Operator, bool) {
+	operatorsMu.RLock()
+	defer operatorsMu.RUnlock()
+
+	if operator, ok := dialectOperators[d][name]; ok {
+		return operator, true
+	}
+
+	operator, ok := Operators[name]
+	return operator, ok
+}
+
 func init() {
 	// register built in operators
-	RegisterOperator("eq", SimpleOperator("= ?"))
+	registerComparisonOperators()
+	registerJSONOperators()
+	registerMatchOperators()
+	registerCompositeOperators()
+	registerPredicateOperators()
+}
+
+// registerComparisonOperators registers the operators comparing the column against the bound value(s).
+func registerComparisonOperators() {
+	RegisterOperator("eq", equalityOperator("= ?", "in"))
 	RegisterOperator("gt", SimpleOperator("> ?"))
 	RegisterOperator("gte", SimpleOperator(">= ?"))
 	RegisterOperator("lte", SimpleOperator("<= ?"))
 	RegisterOperator("lt", SimpleOperator("< ?"))
+	RegisterOperator("neq", equalityOperator("<> ?", "not-in"))
+	RegisterOperator("not-eq", equalityOperator("<> ?", "not-in"))
 
-	RegisterOperator("in", func(c Clause) (string, []any, error) {
-		if err := c.AssertTypeOneOf(reflect.Slice, reflect.Array); err != nil {
-			return "", nil, err
-		}
+	// aliases of the comparisons above, intended for time fields so the intent of the filter reads clearly
+	RegisterOperator("before", SimpleOperator("< ?"))
+	RegisterOperator("after", SimpleOperator("> ?"))
+	RegisterOperator("on-or-before", SimpleOperator("<= ?"))
+	RegisterOperator("on-or-after", SimpleOperator(">= ?"))
 
-		// early return when passed slice is empty
-		if c.reflectedValue.Len() == 0 {
-			return "IN(NULL)", []any{}, nil
+	RegisterOperator("in", inOperator("IN", "1=0", "OR", "%s"))
+	RegisterOperator("not-in", inOperator("NOT IN", "1=1", "AND", "%s"))
+
+	// iin works like in, comparing case-insensitively
+	RegisterOperator("iin", inOperator("IN", "1=0", "OR", "LOWER(%s)"))
+
+	RegisterOperator("between", compositeOperator(func(c Clause) (string, []any, error) {
+		bounds, err := readBounds(c)
+		if err != nil {
+			return "", nil, err
 		}
 
-		placeholders := PlaceholderList(c.reflectedValue.Len())
-		elems, err := readSliceElems(c.reflectedValue)
+		return "BETWEEN ? AND ?", bounds, nil
+	}))
 
+	// range works like between, but compares against both bounds separately
+	RegisterOperator("range", compositeOperator(func(c Clause) (string, []any, error) {
+		bounds, err := readBounds(c)
 		if err != nil {
 			return "", nil, err
 		}
-		return fmt.Sprintf("IN(%s)", placeholders), elems, nil
-	})
 
-	RegisterOperator("not-in", func(c Clause) (string, []any, error) {
-		if err := c.AssertTypeOneOf(reflect.Slice, reflect.Array); err != nil {
+		return c.FullSegment(keepTogether(c, "{col} >= ? AND {col} <= ?")), bounds, nil
+	}))
+
+	// range-open works like range, but treats nil bounds as open ended
+	RegisterOperator("range-open", compositeOperator(rangeOpenOperator))
+
+	// distinct-from compares null-safe, treating NULL as a comparable value, the spelling differs per dialect
+	RegisterOperator("distinct-from", SimpleOperator("IS DISTINCT FROM ?"))
+	RegisterOperator("not-distinct-from", SimpleOperator("IS NOT DISTINCT FROM ?"))
+	RegisterDialectOperator(DialectMySQL, "distinct-from", ColumnOperator("NOT ({col} <=> ?)"))
+	RegisterDialectOperator(DialectMySQL, "not-distinct-from", SimpleOperator("<=> ?"))
+
+	// before-now and after-now compare the column against the current time of the database,
+	// the spelling differs per dialect
+	RegisterOperator("before-now", nowOperator("CURRENT_TIMESTAMP", "<", ">="))
+	RegisterOperator("after-now", nowOperator("CURRENT_TIMESTAMP", ">", "<="))
+	for _, d := range []Dialect{DialectPostgres, DialectMySQL} {
+		RegisterDialectOperator(d, "before-now", nowOperator("NOW()", "<", ">="))
+		RegisterDialectOperator(d, "after-now", nowOperator("NOW()", ">", "<="))
+	}
+
+	// column comparisons compare the column against another column, rather than a value
+	RegisterOperator("eq-col", columnComparison("="))
+	RegisterOperator("neq-col", columnComparison("<>"))
+	RegisterOperator("gt-col", columnComparison(">"))
+	RegisterOperator("gte-col", columnComparison(">="))
+	RegisterOperator("lt-col", columnComparison("<"))
+	RegisterOperator("lte-col", columnComparison("<="))
+}
+
+// registerJSONOperators registers the operators for JSON columns.
+func registerJSONOperators() {
+	// json-contains checks whether a (PostgreSQL) jsonb column contains the given JSON document.
+	// The document is either passed as a string, or as a map or struct which is marshalled to JSON.
+	RegisterOperator("json-contains", compositeOperator(func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.String, reflect.Map, reflect.Struct); err != nil {
 			return "", nil, err
 		}
 
-		// early return when passed slice is empty
-		if c.reflectedValue.Len() == 0 {
-			return "NOT IN(NULL)", []any{}, nil
+		if c.reflectedValue.Kind() == reflect.String {
+			return "@> ?", []any{c.Val}, nil
 		}
 
-		placeholders := PlaceholderList(c.reflectedValue.Len())
-		elems, err := readSliceElems(c.reflectedValue)
+		doc, err := json.Marshal(c.Val)
 		if err != nil {
+			return "", nil, fmt.Errorf("unable to marshal value for operation %s: %w", c.Op, err)
+		}
+
+		return "@> ?", []any{string(doc)}, nil
+	}))
+
+	// json-path compares the value at the path within a JSON column, the spelling differs per dialect
+	RegisterOperator("json-path", jsonPathOperator(func(keys []string) string {
+		return fmt.Sprintf("JSON_EXTRACT({col}, '$.%s')", strings.Join(keys, "."))
+	}))
+	RegisterDialectOperator(DialectMySQL, "json-path", jsonPathOperator(func(keys []string) string {
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT({col}, '$.%s'))", strings.Join(keys, "."))
+	}))
+	RegisterDialectOperator(DialectSQLServer, "json-path", jsonPathOperator(func(keys []string) string {
+		return fmt.Sprintf("JSON_VALUE({col}, '$.%s')", strings.Join(keys, "."))
+	}))
+	RegisterDialectOperator(DialectPostgres, "json-path", jsonPathOperator(func(keys []string) string {
+		last := len(keys) - 1
+		expr := "{col}"
+		for _, key := range keys[:last] {
+			expr += fmt.Sprintf("->'%s'", key)
+		}
+		return expr + fmt.Sprintf("->>'%s'", keys[last])
+	}))
+
+	// length operators compare the number of elements in a JSON array column, the spelling differs per dialect
+	for name, symbol := range map[string]string{"length-eq": "=", "length-gte": ">=", "length-lte": "<="} {
+		RegisterOperator(name, lengthOperator("json_array_length({col})", symbol))
+		RegisterDialectOperator(DialectPostgres, name, lengthOperator("jsonb_array_length({col})", symbol))
+		RegisterDialectOperator(DialectMySQL, name, lengthOperator("JSON_LENGTH({col})", symbol))
+		RegisterDialectOperator(DialectSQLServer, name, lengthOperator("(SELECT COUNT(*) FROM OPENJSON({col}))", symbol))
+	}
+}
+
+// registerMatchOperators registers the operators matching strings and (PostgreSQL) arrays.
+func registerMatchOperators() {
+	// array operators for (PostgreSQL) array columns, binding the slice as a single array value
+	RegisterOperator("array-overlap", ArrayOperator("&& ?"))
+	RegisterOperator("array-contains", ArrayOperator("@> ?"))
+
+	// any works like in, but binds the slice as a single (PostgreSQL) array, keeping the query
+	// the same regardless of the number of elements
+	RegisterOperator("any", ArrayOperator("= ANY(?)"))
+
+	// fts performs a (PostgreSQL) full-text search on the column for the given search string
+	RegisterOperator("fts", func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.String); err != nil {
 			return "", nil, err
 		}
-		return fmt.Sprintf("NOT IN(%s)", placeholders), elems, nil
+
+		return c.FullSegment("to_tsvector({col}) @@ plainto_tsquery(?)"), []any{c.Val}, nil
 	})
 
-	RegisterOperator("between", func(c Clause) (string, []any, error) {
+	// ieq compares strings case-insensitively, portable across dialects lacking ILIKE
+	RegisterOperator("ieq", func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.String); err != nil {
+			return "", nil, err
+		}
+
+		return c.FullSegment("LOWER({col}) = LOWER(?)"), []any{c.Val}, nil
+	})
+
+	// regexp matches the column against a regular expression, the spelling differs per dialect
+	RegisterOperator("regexp", stringOperator("REGEXP ?"))
+	RegisterDialectOperator(DialectPostgres, "regexp", stringOperator("~ ?"))
+}
+
+// registerCompositeOperators registers the operators rendering subqueries, groups and raw segments.
+func registerCompositeOperators() {
+	// exists checks whether the subquery results in any rows, see Subquery
+	RegisterOperator("exists", subqueryOperator(func(c Clause, query string) string {
+		c.markFull()
+		return fmt.Sprintf("EXISTS (%s)", query)
+	}))
+
+	// compare the column against all or any of the rows the subquery results in, see Subquery
+	RegisterOperator("gt-all", subqueryOperator(func(_ Clause, query string) string {
+		return fmt.Sprintf("> ALL (%s)", query)
+	}))
+	RegisterOperator("lt-all", subqueryOperator(func(_ Clause, query string) string {
+		return fmt.Sprintf("< ALL (%s)", query)
+	}))
+	RegisterOperator("eq-any", subqueryOperator(func(_ Clause, query string) string {
+		return fmt.Sprintf("= ANY (%s)", query)
+	}))
+
+	// groups renders a slice of filter structs as groups of clauses joined using AND, joining the groups using OR
+	RegisterOperator("groups", compositeOperator(func(c Clause) (string, []any, error) {
 		if err := c.AssertTypeOneOf(reflect.Slice, reflect.Array); err != nil {
 			return "", nil, err
 		}
 
-		if c.reflectedValue.Len() < 2 {
-			return "", nil, fmt.Errorf("operation between expects two elements in its slice")
+		// the clauses within a group are always joined using AND, regardless of the chaining strategy
+		opts := groupOpts(c, ChainingStrategyAnd)
+
+		var (
+			groups []string
+			args   []any
+		)
+
+		for i := 0; i < c.reflectedValue.Len(); i++ {
+			sql, groupArgs, err := renderGroup(c.reflectedValue.Index(i).Interface(), opts)
+			if err != nil {
+				return "", nil, fmt.Errorf("group %d: %w", i, err)
+			}
+
+			// a group without any clauses matches all rows
+			if sql == "" {
+				return c.FullSegment("1=1"), []any{}, nil
+			}
+
+			groups = append(groups, fmt.Sprintf("(%s)", sql))
+			args = append(args, groupArgs...)
 		}
 
-		elems, err := readSliceElems(c.reflectedValue)
-		if err != nil {
-			return "", nil, err
+		// the groups are rendered already, so the column isn't placed within them
+		c.markFull()
+		return fmt.Sprintf("(%s)", strings.Join(groups, " OR ")), args, nil
+	}))
+
+	// group renders the clauses of a filter struct as a parenthesized group, see the group tag flag
+	RegisterOperator("group", groupOperator("(%s)"))
+	RegisterOperator("not-group", groupOperator("NOT (%s)"))
+
+	// raw writes the segment of a Raw value into the query as is, see Raw
+	RegisterOperator("raw", compositeOperator(func(c Clause) (string, []any, error) {
+		raw, ok := c.Val.(Raw)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: expected Raw; got %T for operation %s", ErrTypeMismatch, c.Val, c.Op)
 		}
 
-		return "BETWEEN ? AND ?", elems[:2], nil
-	})
+		if strings.Contains(raw.SQL, ColumnToken) {
+			return c.FullSegment(raw.SQL), boundAsIs(c, raw.Args), nil
+		}
+
+		return raw.SQL, boundAsIs(c, raw.Args), nil
+	}))
+}
 
+// registerPredicateOperators registers the operators comparing against NULL, TRUE or FALSE,
+// without binding a value.
+func registerPredicateOperators() {
 	RegisterOperator("is-null", func(c Clause) (string, []any, error) {
 		if c.reflectedValue.Bool() {
 			return "IS NULL", []any{}, nil
@@ -115,6 +465,362 @@ func init() {
 
 		return "IS NULL", []any{}, nil
 	})
+
+	RegisterOperator("is-true", func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.Bool); err != nil {
+			return "", nil, err
+		}
+
+		if c.reflectedValue.Bool() {
+			return "= TRUE", []any{}, nil
+		}
+
+		return "= FALSE", []any{}, nil
+	})
+
+	RegisterOperator("is-false", func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.Bool); err != nil {
+			return "", nil, err
+		}
+
+		if c.reflectedValue.Bool() {
+			return "= FALSE", []any{}, nil
+		}
+
+		return "= TRUE", []any{}, nil
+	})
+}
+
+// inOperator returns an operator expanding the slice into a placeholder per element, eg: `IN(?,?,?)`.
+// An empty slice results in a comparison against NULL, or in the logical segment when configured
+// using WithEmptyInBehavior. Slices exceeding the chunk size configured using WithInChunkSize are
+// split into multiple comparisons, joined using the conjunction.
+//
+// Both the column and each placeholder are formatted using wrap, eg: `LOWER(%s)` results in
+// `LOWER(col) IN(LOWER(?),LOWER(?))`.
+func inOperator(keyword, emptyLogical, conjunction, wrap string) Operator {
+	column := fmt.Sprintf(wrap, ColumnToken)
+	placeholders := func(n int) string {
+		return strings.Repeat(","+fmt.Sprintf(wrap, "?"), n)[1:]
+	}
+
+	return func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.Slice, reflect.Array); err != nil {
+			return "", nil, err
+		}
+
+		opts := c.options()
+		n := c.reflectedValue.Len()
+
+		// early return when passed slice is empty
+		if n == 0 {
+			if opts.EmptyInBehavior == EmptyInLogical {
+				return c.FullSegment(emptyLogical), []any{}, nil
+			}
+			return c.FullSegment(fmt.Sprintf("%s %s(NULL)", column, keyword)), []any{}, nil
+		}
+
+		if opts.MaxInElements > 0 && n > opts.MaxInElements {
+			return "", nil, fmt.Errorf(
+				"%w: operation %s got %d elements; the maximum is %d",
+				ErrTooManyElements, c.Op, n, opts.MaxInElements,
+			)
+		}
+
+		elems, err := readSliceElems(c.reflectedValue)
+		if err != nil {
+			return "", nil, err
+		}
+
+		size := opts.InChunkSize
+		if size <= 0 || n <= size {
+			return c.FullSegment(fmt.Sprintf("%s %s(%s)", column, keyword, placeholders(n))), elems, nil
+		}
+
+		var chunks []string
+		for start := 0; start < n; start += size {
+			end := start + size
+			if end > n {
+				end = n
+			}
+
+			chunks = append(chunks, fmt.Sprintf("%s %s(%s)", column, keyword, placeholders(end-start)))
+		}
+
+		sep := fmt.Sprintf(" %s ", conjunction)
+		return c.FullSegment(fmt.Sprintf("(%s)", strings.Join(chunks, sep))), elems, nil
+	}
+}
+
+// nowOperator returns an operator comparing the column against the current time, as returned by the now function
+// of the database. Like is-null, it works on booleans: true compares using the symbol, false using its negation.
+func nowOperator(now, symbol, negated string) Operator {
+	return func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.Bool); err != nil {
+			return "", nil, err
+		}
+
+		if c.reflectedValue.Bool() {
+			return fmt.Sprintf("%s %s", symbol, now), []any{}, nil
+		}
+
+		return fmt.Sprintf("%s %s", negated, now), []any{}, nil
+	}
+}
+
+// lengthOperator returns an operator comparing the length of the column, as returned by the length expression,
+// against the (integer) value of the clause.
+func lengthOperator(length, symbol string) Operator {
+	return func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		); err != nil {
+			return "", nil, err
+		}
+
+		return c.FullSegment(fmt.Sprintf("%s %s ?", length, symbol)), []any{c.Val}, nil
+	}
+}
+
+// readBounds reads a lower and upper bound from the value of the clause, which is either a slice
+// or array of (at least) two elements, or a struct with exactly two exported fields, eg:
+//
+//	type PriceRange struct {
+//		Lo, Hi float64
+//	}
+//
+// The bounds are returned in the order of the elements or the order the fields are declared in.
+func readBounds(c Clause) ([]any, error) {
+	if err := c.AssertTypeOneOf(reflect.Slice, reflect.Array, reflect.Struct); err != nil {
+		return nil, err
+	}
+
+	v := c.reflectedValue
+	if v.Kind() == reflect.Struct {
+		var bounds []any
+		for _, field := range reflect.VisibleFields(v.Type()) {
+			if !field.IsExported() || field.Anonymous {
+				continue
+			}
+
+			bound, err := readValue(v.FieldByIndex(field.Index))
+			if err != nil {
+				return nil, err
+			}
+			bounds = append(bounds, bound)
+		}
+
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf(
+				"%w: operation %s expects a struct with two exported fields; got %d", ErrTypeMismatch, c.Op, len(bounds),
+			)
+		}
+
+		return bounds, nil
+	}
+
+	if v.Len() < 2 {
+		return nil, fmt.Errorf("%w: operation %s expects two elements in its slice", ErrTypeMismatch, c.Op)
+	}
+
+	elems, err := readSliceElems(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return elems[:2], nil
+}
+
+// rangeOpenOperator compares the column against the bounds that are present, read like readBounds, eg:
+// a *[]*time.Time holding a from and to date picked in a UI. A nil low bound results in `{col} <= ?`,
+// a nil high bound in `{col} >= ?` and the clause is skipped when both bounds are nil.
+func rangeOpenOperator(c Clause) (string, []any, error) {
+	bounds, err := readBounds(c)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		segments []string
+		args     = []any{}
+	)
+
+	for i, comparison := range []string{"{col} >= ?", "{col} <= ?"} {
+		if bounds[i] == nil {
+			continue
+		}
+
+		segments = append(segments, comparison)
+		args = append(args, bounds[i])
+	}
+
+	switch len(segments) {
+	case 0:
+		return c.FullSegment(""), args, nil
+	case 1:
+		return c.FullSegment(segments[0]), args, nil
+	}
+
+	return c.FullSegment(keepTogether(c, "{col} >= ? AND {col} <= ?")), args, nil
+}
+
+// keepTogether parenthesizes a segment of multiple comparisons joined using AND (eg: both bounds of a range)
+// when the clauses are chained using OR, unless the clauses are wrapped already.
+func keepTogether(c Clause, sql string) string {
+	if opts := c.options(); opts.ChainingStrategy == ChainingStrategyOr && !opts.WrapClauses {
+		return fmt.Sprintf("(%s)", sql)
+	}
+
+	return sql
+}
+
+// jsonPathPattern matches the (dot separated) keys accepted by the json-path operator, eg: `address.country`.
+// The path is written into the query as is, so it's restricted to letters, digits and underscores.
+var jsonPathPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// jsonPathOperator returns an operator comparing the value at the path, passed using the path parameter
+// of the tag, to the value of the clause. The extract function renders the expression reading the keys
+// of the path from the column.
+func jsonPathOperator(extract func(keys []string) string) Operator {
+	return func(c Clause) (string, []any, error) {
+		path, err := readJSONPath(c.Op, c.params)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return c.FullSegment(extract(strings.Split(path, ".")) + " = ?"), []any{c.Val}, nil
+	}
+}
+
+// readJSONPath returns the path passed using the path parameter of the tag, see jsonPathOperator.
+func readJSONPath(op string, params map[string]string) (string, error) {
+	path, ok := params["path"]
+	if !ok {
+		return "", fmt.Errorf("%w: operation %s expects a path, eg: path=country", ErrInvalidTag, op)
+	}
+
+	if !jsonPathPattern.MatchString(path) {
+		return "", fmt.Errorf("%w: operation %s: invalid path %q", ErrInvalidTag, op, path)
+	}
+
+	return path, nil
+}
+
+// columnComparison returns an operator comparing the column against the column named by the (string) value
+// of the clause, eg: `updated_at > created_at`. As the other column is written into the query as is, it has
+// to be a plain identifier (see identifierPattern) and it's subject to the allowed columns, when configured.
+func columnComparison(symbol string) Operator {
+	return func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.String); err != nil {
+			return "", nil, err
+		}
+
+		other := c.reflectedValue.String()
+		if !identifierPattern.MatchString(other) {
+			return "", nil, fmt.Errorf("%w: %q", ErrInvalidColumn, other)
+		}
+
+		if allowed := c.options().AllowedColumns; allowed != nil && !allowed[other] {
+			return "", nil, fmt.Errorf("%w: %q", ErrColumnNotAllowed, other)
+		}
+
+		return fmt.Sprintf("%s %s", symbol, other), []any{}, nil
+	}
+}
+
+// groupOpts returns the options to render the clauses of a group with, joining them using the strategy.
+func groupOpts(c Clause, strategy ChainingStrategy) *Opts {
+	opts := *c.options()
+	opts.ChainingStrategy = strategy
+	opts.Separator = ""
+	opts.PrettyPrint = false
+	opts.WrapClauses = false
+
+	// overrides apply to the fields of the filter holding the group
+	opts.Overrides = nil
+
+	return &opts
+}
+
+// groupStrategy returns the chaining strategy the clauses of the group are joined with.
+func groupStrategy(c Clause) ChainingStrategy {
+	if chain, ok := c.Param("chain"); ok {
+		return ChainingStrategy(strings.ToUpper(chain))
+	}
+
+	return c.options().ChainingStrategy
+}
+
+// renderGroup renders the clauses of the filter struct, without completing the query.
+// The values are bound using the columns of the clauses within the group, see boundArg.
+func renderGroup(f any, opts *Opts) (string, []any, error) {
+	clauses, err := buildClauses(f, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	segs, err := renderSegments(clauses, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sql, args := joinSegments(segs, opts)
+	for i, col := range segmentColumns(segs) {
+		args[i] = boundArg{col: col, value: args[i]}
+	}
+
+	return sql, args, nil
+}
+
+// groupOperator returns an operator rendering the clauses of a filter struct, formatted using the format.
+// The clauses are joined using the chaining strategy, unless the tag sets another (eg: `chain=or`).
+// A group without any clauses is skipped, rather than rendering an empty group.
+func groupOperator(format string) Operator {
+	return compositeOperator(func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.Struct); err != nil {
+			return "", nil, err
+		}
+
+		strategy := groupStrategy(c)
+		if !strategy.valid() {
+			return "", nil, fmt.Errorf("%w: %q", ErrInvalidChainingStrategy, strategy)
+		}
+
+		sql, args, err := renderGroup(c.Val, groupOpts(c, strategy))
+		if err != nil {
+			return "", nil, err
+		}
+
+		if sql == "" {
+			return c.FullSegment(""), []any{}, nil
+		}
+
+		// the group is rendered already, so the column isn't placed within it
+		c.markFull()
+		return fmt.Sprintf(format, sql), args, nil
+	})
+}
+
+// subqueryOperator returns an operator embedding the subquery (a string or Subquery) into the segment
+// returned by render. The subquery is written into the query as is, only its args are bound.
+func subqueryOperator(render func(c Clause, query string) string) Operator {
+	return compositeOperator(func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.String, reflect.Struct); err != nil {
+			return "", nil, err
+		}
+
+		if c.reflectedValue.Kind() == reflect.String {
+			return render(c, fmt.Sprint(c.Val)), []any{}, nil
+		}
+
+		subquery, ok := c.Val.(Subquery)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: expected Subquery; got %T for operation %s", ErrTypeMismatch, c.Val, c.Op)
+		}
+
+		return render(c, subquery.Query), boundAsIs(c, subquery.Args), nil
+	})
 }
 
 // SimpleOperator is a shorthand function for creating operators with a one-to-one matching
@@ -127,3 +833,198 @@ func SimpleOperator(r string) Operator {
 		return r, []any{c.Val}, nil
 	}
 }
+
+// slicePromotions maps the equality operators to the operators slices are compared with instead.
+var slicePromotions = map[string]string{
+	"eq":     "in",
+	"neq":    "not-in",
+	"not-eq": "not-in",
+}
+
+// nullPromotions maps the equality operators to whether nil values are compared using IS NULL (true) or
+// IS NOT NULL (false), when nil values are compared rather than skipped. See WithNilAsIsNull.
+var nullPromotions = map[string]bool{
+	"eq":     true,
+	"neq":    false,
+	"not-eq": false,
+}
+
+// equalityOperator works like SimpleOperator, but rejects slices and arrays (which would be bound as a single value),
+// suggesting the operator to use instead. See WithAutoInForSlices to promote these clauses automatically.
+func equalityOperator(r, suggested string) Operator {
+	return func(c Clause) (string, []any, error) {
+		if kind := c.reflectedValue.Kind(); kind == reflect.Slice || kind == reflect.Array {
+			return "", nil, fmt.Errorf(
+				"%w: operation %s doesn't support %s values, use op=%s instead", ErrTypeMismatch, c.Op, kind, suggested,
+			)
+		}
+
+		return r, []any{c.Val}, nil
+	}
+}
+
+// stringOperator works like SimpleOperator, but only accepts string values.
+func stringOperator(r string) Operator {
+	return func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.String); err != nil {
+			return "", nil, err
+		}
+
+		return r, []any{c.Val}, nil
+	}
+}
+
+// ColumnOperator is a shorthand function for creating operators with a one-to-one matching
+// between column and value, for which the column is placed within the query segment.
+// The template references the column using ColumnToken.
+//
+// eg: ColumnOperator("LOWER({col}) = LOWER(?)") will return a function that will return the query
+// segment "LOWER(email) = LOWER(?)" and the value of the Clause struct as the argument.
+func ColumnOperator(tmpl string) Operator {
+	return func(c Clause) (string, []any, error) {
+		return c.FullSegment(tmpl), []any{c.Val}, nil
+	}
+}
+
+// ArrayOperator is a shorthand function for creating operators that bind a slice or array
+// as a single (array) value, rather than expanding it into a placeholder per element like `in` does.
+//
+// eg: ArrayOperator("&& ?") will return a function that will return the query segment "&& ?"
+// and the slice as the only argument.
+//
+// The slice is bound as is, which drivers like pgx support out of the box. When using
+// github.com/lib/pq, the argument needs to be wrapped using pq.Array, see WithArrayBinder.
+func ArrayOperator(r string) Operator {
+	return func(c Clause) (string, []any, error) {
+		if err := c.AssertTypeOneOf(reflect.Slice, reflect.Array); err != nil {
+			return "", nil, err
+		}
+
+		return r, []any{AsArray(c.reflectedValue.Interface())}, nil
+	}
+}
+
+// arrayArg marks a value returned by an operator to be bound as a single array value, see AsArray.
+type arrayArg struct {
+	value any
+}
+
+// boundArg holds a value returned by an operator that's already bound to the query, along with the column of
+// the clause it originates from. The values of a group are bound within the group using the columns of its own
+// clauses, rather than the (empty) column of the group clause.
+type boundArg struct {
+	col   string
+	value any
+}
+
+// boundAsIs marks the values passed along with a segment (eg: the args of a Subquery, typically returned by ToSQL
+// already) as bound, so they're bound as is rather than being transformed again under the column of the clause.
+func boundAsIs(c Clause, args []any) []any {
+	bound := make([]any, len(args))
+	for i, arg := range args {
+		bound[i] = boundArg{col: c.Col, value: arg}
+	}
+
+	return bound
+}
+
+// AsArray marks a slice or array returned by an operator as a single array value, bound using a single
+// placeholder rather than being expanded. The slice is bound as is, or wrapped by the binder passed using
+// WithArrayBinder when set. Operators binding the slice of the field use ArrayOperator, AsArray is meant
+// for operators composing the slice themselves, eg:
+//
+//	RegisterOperator("tags-csv", func(c Clause) (string, []any, error) {
+//		return "&& ?", []any{AsArray(strings.Split(c.Val.(string), ","))}, nil
+//	})
+func AsArray(v any) any {
+	return arrayArg{value: v}
+}
+
+// Not wraps an operator, negating the query segment it emits. This allows reusing
+// any registered operator in its negated form without registering a parallel operator, eg:
+//
+//	RegisterOperator("not-like", Not(SimpleOperator("LIKE ?")))
+//
+// results in the query segment `NOT (title LIKE ?)`. The values returned by the wrapped
+// operator are passed along untouched, so their placeholders are rewritten as usual. The value of the clause
+// is read like it is for the wrapped operator, eg: Not(Operators["between"]) accepts a struct holding both bounds.
+func Not(op Operator) Operator {
+	return func(c Clause) (string, []any, error) {
+		sql, args, skip, err := renderOperator(op, c)
+		if err != nil || skip {
+			return c.FullSegment(""), args, err
+		}
+
+		// the segment of the operator is rendered already, including its column
+		c.markFull()
+		return fmt.Sprintf("NOT (%s)", sql), args, nil
+	}
+}
+
+// ColumnToken marks the position of the column within a full query segment, see Clause.FullSegment.
+const ColumnToken = "{col}"
+
+// segmentState records whether the operator of a clause marked its segment as complete, see Clause.FullSegment.
+type segmentState struct {
+	full bool
+
+	// whether the operator interprets composite values itself, see compositeOperator
+	composite bool
+}
+
+// renderOperator calls the operator for the clause and returns the query segment including the column,
+// either placed by the operator in a full segment or prepended to the segment. It reports whether
+// the clause is skipped, which is the case for an empty full segment.
+func renderOperator(op Operator, c Clause) (string, []any, bool, error) {
+	// the state is shared by the copies of the clause passed along, eg: to an operator wrapped by Not
+	outer := c.segment
+	c.segment = &segmentState{}
+
+	sql, args, err := op(c)
+
+	// an operator rendering another (eg: Not) reads composite values when the operator it renders does
+	if outer != nil && c.segment.composite {
+		outer.composite = true
+	}
+
+	// composite values (eg: a struct) are only supported by the operators interpreting them, see compositeOperator
+	if c.valueErr != nil && !c.segment.composite {
+		return "", nil, false, c.valueErr
+	}
+
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	if !c.segment.full {
+		return fmt.Sprintf("%s %s", c.Col, sql), args, false, nil
+	}
+
+	return sql, args, sql == "", nil
+}
+
+// compositeOperator wraps an operator interpreting map and struct values itself (eg: the bounds of between),
+// rather than having them read as a single value, which is unsupported for structs. The operator receives
+// the value as is, and the clause is skipped for a nil map or an empty slice, like it is for a nil pointer.
+//
+// Reading composite values is a property of the operator rather than of its name, so it's kept when the operator
+// is registered under another name (eg: using AliasOperator or WithOperator) or rendered by another operator
+// (eg: Not).
+func compositeOperator(op Operator) Operator {
+	return func(c Clause) (string, []any, error) {
+		if c.segment != nil {
+			c.segment.composite = true
+		}
+
+		if !isComposite(c.reflectedValue) {
+			return op(c)
+		}
+
+		c.Val = readComposite(c.reflectedValue)
+		if c.Val == nil {
+			return c.FullSegment(""), []any{}, nil
+		}
+
+		return op(c)
+	}
+}
@@ -0,0 +1,1266 @@
+package queryfilter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeqOperator(t *testing.T) {
+	type filter struct {
+		Status   string `filter:"status,op=neq"`
+		Priority int    `filter:"priority,op=not-eq"`
+	}
+
+	q, v, e := ToSQL(filter{Status: "done", Priority: 1})
+	assert.Nil(t, e)
+	assert.Equal(t, "status <> ? AND priority <> ?", q)
+	assert.Equal(t, []any{"done", int64(1)}, v)
+}
+
+func TestNotOperator(t *testing.T) {
+	Operators["not-like"] = Not(SimpleOperator("LIKE ?"))
+	Operators["not-between"] = Not(Operators["between"])
+	Operators["not-not-like"] = Not(Not(SimpleOperator("LIKE ?")))
+	defer func() {
+		delete(Operators, "not-like")
+		delete(Operators, "not-between")
+		delete(Operators, "not-not-like")
+	}()
+
+	type filter struct {
+		Title  string    `filter:"title,op=not-like"`
+		Prices []float64 `filter:"price,op=not-between"`
+		Name   string    `filter:"name,op=not-not-like"`
+	}
+
+	f := filter{Title: "%draft%", Prices: []float64{10, 20}, Name: "bob%"}
+
+	q, v, e := ToSQL(f, WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(t, "NOT (title LIKE $1) AND NOT (price BETWEEN $2 AND $3) AND NOT (NOT (name LIKE $4))", q)
+	assert.Equal(t, []any{"%draft%", float64(10), float64(20), "bob%"}, v)
+}
+
+func TestNotOperatorError(t *testing.T) {
+	Operators["not-in-test"] = Not(Operators["in"])
+	defer delete(Operators, "not-in-test")
+
+	type filter struct {
+		Title string `filter:"title,op=not-in-test"`
+	}
+
+	_, _, e := ToSQL(filter{Title: "oops"})
+	assert.ErrorContains(t, e, "expected slice or array; got string")
+}
+
+func TestJSONContainsOperator(t *testing.T) {
+	type metadata struct {
+		Country string `json:"country"`
+	}
+
+	type filter struct {
+		Raw    *string           `filter:"raw,op=json-contains"`
+		Map    map[string]string `filter:"map,op=json-contains"`
+		Struct *metadata         `filter:"struct,op=json-contains"`
+	}
+
+	raw := `{"country":"NL"}`
+	f := filter{
+		Raw:    &raw,
+		Map:    map[string]string{"country": "NL"},
+		Struct: &metadata{Country: "NL"},
+	}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "raw @> ? AND map @> ? AND struct @> ?", q)
+	assert.Equal(t, []any{raw, raw, raw}, v)
+
+	q, v, e = ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+}
+
+func TestJSONContainsOperatorErrors(t *testing.T) {
+	type wrongType struct {
+		Value int `filter:"value,op=json-contains"`
+	}
+
+	_, _, e := ToSQL(wrongType{Value: 1})
+	assert.ErrorContains(t, e, "expected string, map or struct; got int")
+
+	type unmarshallable struct {
+		Value map[string]any `filter:"value,op=json-contains"`
+	}
+
+	_, _, e = ToSQL(unmarshallable{Value: map[string]any{"fn": func() {}}})
+	assert.ErrorContains(t, e, "unable to marshal value for operation json-contains")
+}
+
+func TestArrayOperators(t *testing.T) {
+	type filter struct {
+		AnyTag  *[]string `filter:"tags,op=array-overlap"`
+		AllTags []int     `filter:"tag_ids,op=array-contains"`
+	}
+
+	tags := []string{"go", "sql"}
+	f := filter{
+		AnyTag:  &tags,
+		AllTags: []int{1, 2, 3},
+	}
+
+	q, v, e := ToSQL(f, WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(t, "tags && $1 AND tag_ids @> $2", q)
+	assert.Equal(t, []any{[]string{"go", "sql"}, []int{1, 2, 3}}, v)
+}
+
+func TestArrayOperatorsWithArrayBinder(t *testing.T) {
+	type wrapped struct {
+		value any
+	}
+
+	type filter struct {
+		Tags     []string `filter:"tags,op=array-overlap"`
+		Statuses []string `filter:"status,op=in"`
+		CSV      *string  `filter:"labels,op=labels-csv"`
+	}
+
+	labels := "bug,urgent"
+	f := filter{Tags: []string{"go"}, Statuses: []string{"todo"}, CSV: &labels}
+
+	csv := func(c Clause) (string, []any, error) {
+		return "&& ?", []any{AsArray(strings.Split(c.Val.(string), ","))}, nil
+	}
+	binder := func(v any) any { return wrapped{v} }
+
+	q, v, e := ToSQL(f, WithOperator("labels-csv", csv), WithArrayBinder(binder))
+	assert.Nil(t, e)
+	assert.Equal(t, "tags && ? AND status IN(?) AND labels && ?", q)
+	assert.Equal(t, []any{wrapped{[]string{"go"}}, "todo", wrapped{[]string{"bug", "urgent"}}}, v)
+
+	_, v, e = ToSQL(f, WithOperator("labels-csv", csv))
+	assert.Nil(t, e)
+	assert.Equal(t, []any{[]string{"go"}, "todo", []string{"bug", "urgent"}}, v)
+
+	type grouped struct {
+		Group filter `filter:",group"`
+	}
+
+	q, v, e = ToSQL(grouped{Group: f}, WithOperator("labels-csv", csv), WithArrayBinder(binder))
+	assert.Nil(t, e)
+	assert.Equal(t, "(tags && ? AND status IN(?) AND labels && ?)", q)
+	assert.Equal(t, []any{wrapped{[]string{"go"}}, "todo", wrapped{[]string{"bug", "urgent"}}}, v)
+}
+
+func TestAnyOperator(t *testing.T) {
+	type filter struct {
+		Statuses *[]string `filter:"status,op=any"`
+		Name     *string   `filter:"name"`
+	}
+
+	name := "jane"
+	for _, statuses := range [][]string{{"todo"}, {"todo", "doing", "done"}} {
+		statuses := statuses
+		q, v, e := ToSQL(filter{Statuses: &statuses, Name: &name}, WithDialect(DialectPostgres))
+		assert.Nil(t, e)
+		assert.Equal(t, "status = ANY($1) AND name = $2", q)
+		assert.Equal(t, []any{statuses, name}, v)
+	}
+}
+
+func TestArrayOperatorWrongType(t *testing.T) {
+	type filter struct {
+		Tag string `filter:"tags,op=array-overlap"`
+	}
+
+	_, _, e := ToSQL(filter{Tag: "go"})
+	assert.ErrorContains(t, e, "expected slice or array; got string for operation array-overlap")
+}
+
+func TestFTSOperator(t *testing.T) {
+	type filter struct {
+		Search *string `filter:"title,op=fts"`
+		Status string  `filter:"status"`
+	}
+
+	search := "code review"
+	q, v, e := ToSQL(filter{Search: &search, Status: "todo"}, WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(t, "to_tsvector(title) @@ plainto_tsquery($1) AND status = $2", q)
+	assert.Equal(t, []any{"code review", "todo"}, v)
+}
+
+func TestFullSegmentDecorated(t *testing.T) {
+	// wraps the segment of another operator in parentheses, keeping it complete when it's a full segment
+	parenthesized := func(op string) Operator {
+		return func(c Clause) (string, []any, error) {
+			sql, args, err := Operators[op](c)
+			if err != nil || sql == "" {
+				return sql, args, err
+			}
+
+			return "(" + sql + ")", args, nil
+		}
+	}
+
+	type filter struct {
+		Statuses []string `filter:"status,op=paren-in"`
+		Email    *string  `filter:"email,op=paren-ieq"`
+		Name     *string  `filter:"name,op=not-ieq"`
+		Points   []*int   `filter:"story_points,op=not-range-open"`
+	}
+
+	email, name := "Jane@Example.com", "Jane"
+	f := filter{Statuses: []string{"todo", "done"}, Email: &email, Name: &name, Points: []*int{nil, nil}}
+
+	q, v, e := ToSQL(f,
+		WithOperator("paren-in", parenthesized("in")),
+		WithOperator("paren-ieq", parenthesized("ieq")),
+		WithOperator("not-ieq", Not(Operators["ieq"])),
+		WithOperator("not-range-open", Not(Operators["range-open"])),
+	)
+
+	assert.Nil(t, e)
+	assert.Equal(t, "(status IN(?,?)) AND (LOWER(email) = LOWER(?)) AND NOT (LOWER(name) = LOWER(?))", q)
+	assert.Equal(t, []any{"todo", "done", "Jane@Example.com", "Jane"}, v)
+}
+
+func TestFullSegmentWithoutColumn(t *testing.T) {
+	Operators["always"] = func(c Clause) (string, []any, error) {
+		return c.FullSegment("1=1"), []any{}, nil
+	}
+	defer delete(Operators, "always")
+
+	type filter struct {
+		Always bool `filter:"ignored,op=always"`
+	}
+
+	q, v, e := ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "1=1", q)
+	assert.Empty(t, v)
+}
+
+func TestColumnOperator(t *testing.T) {
+	defer RestoreOperators(SnapshotOperators())
+
+	RegisterOperator("year", ColumnOperator("EXTRACT(YEAR FROM {col}) = ?"))
+	RegisterOperator("not-year", Not(ColumnOperator("EXTRACT(YEAR FROM {col}) = ?")))
+
+	type filter struct {
+		Year       int `filter:"created_at,op=year"`
+		ExceptYear int `filter:"updated_at,op=not-year"`
+		MinAge     int `filter:"age,op=gt"`
+	}
+
+	q, v, e := ToSQL(filter{Year: 2023, ExceptYear: 2022, MinAge: 18})
+	assert.Nil(t, e)
+	assert.Equal(t, "EXTRACT(YEAR FROM created_at) = ? AND NOT (EXTRACT(YEAR FROM updated_at) = ?) AND age > ?", q)
+	assert.Equal(t, []any{int64(2023), int64(2022), int64(18)}, v)
+}
+
+func TestRegexpOperator(t *testing.T) {
+	type filter struct {
+		Code *string `filter:"code,op=regexp"`
+	}
+
+	pattern := "^A[0-9]+$"
+	f := filter{Code: &pattern}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "code REGEXP ?", q)
+	assert.Equal(t, []any{pattern}, v)
+
+	q, v, e = ToSQL(f, WithDialect(DialectMySQL))
+	assert.Nil(t, e)
+	assert.Equal(t, "code REGEXP ?", q)
+	assert.Equal(t, []any{pattern}, v)
+
+	q, v, e = ToSQL(f, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "code ~ $1", q)
+	assert.Equal(t, []any{pattern}, v)
+
+	type wrongType struct {
+		Code int `filter:"code,op=regexp"`
+	}
+
+	_, _, e = ToSQL(wrongType{Code: 1})
+	assert.ErrorContains(t, e, "expected string; got int for operation regexp")
+}
+
+func TestDistinctFromOperators(t *testing.T) {
+	type filter struct {
+		Status   *string `filter:"status,op=distinct-from"`
+		Assignee *string `filter:"assignee,op=not-distinct-from"`
+	}
+
+	status, assignee := "done", "jane"
+	f := filter{Status: &status, Assignee: &assignee}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "status IS DISTINCT FROM ? AND assignee IS NOT DISTINCT FROM ?", q)
+	assert.Equal(t, []any{status, assignee}, v)
+
+	q, v, e = ToSQL(f, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "status IS DISTINCT FROM $1 AND assignee IS NOT DISTINCT FROM $2", q)
+	assert.Equal(t, []any{status, assignee}, v)
+
+	q, v, e = ToSQL(f, WithDialect(DialectMySQL))
+	assert.Nil(t, e)
+	assert.Equal(t, "NOT (status <=> ?) AND assignee <=> ?", q)
+	assert.Equal(t, []any{status, assignee}, v)
+}
+
+func TestIsTrueIsFalseOperators(t *testing.T) {
+	type filter struct {
+		Active   *bool `filter:"active,op=is-true"`
+		Archived *bool `filter:"archived,op=is-false"`
+	}
+
+	trueVal, falseVal := true, false
+
+	cases := []struct {
+		f filter
+		e string
+	}{
+		{f: filter{Active: &trueVal, Archived: &trueVal}, e: "active = TRUE AND archived = FALSE"},
+		{f: filter{Active: &falseVal, Archived: &falseVal}, e: "active = FALSE AND archived = TRUE"},
+		{f: filter{}, e: ""},
+	}
+
+	for _, c := range cases {
+		q, v, e := ToSQL(c.f)
+		assert.Nil(t, e)
+		assert.Equal(t, c.e, q)
+		assert.Empty(t, v)
+	}
+
+	type wrongType struct {
+		Active string `filter:"active,op=is-true"`
+	}
+
+	_, _, e := ToSQL(wrongType{Active: "yes"})
+	assert.ErrorContains(t, e, "expected bool; got string for operation is-true")
+}
+
+func TestNowOperators(t *testing.T) {
+	type filter struct {
+		Expired  *bool `filter:"expires_at,op=before-now"`
+		Upcoming *bool `filter:"starts_at,op=after-now"`
+	}
+
+	trueVal, falseVal := true, false
+
+	cases := []struct {
+		f filter
+		d Dialect
+		e string
+	}{
+		{f: filter{Expired: &trueVal, Upcoming: &trueVal}, e: "expires_at < CURRENT_TIMESTAMP AND starts_at > CURRENT_TIMESTAMP"},
+		{f: filter{Expired: &falseVal, Upcoming: &falseVal}, e: "expires_at >= CURRENT_TIMESTAMP AND starts_at <= CURRENT_TIMESTAMP"},
+		{f: filter{Expired: &trueVal, Upcoming: &falseVal}, d: DialectPostgres, e: "expires_at < NOW() AND starts_at <= NOW()"},
+		{f: filter{Expired: &trueVal}, d: DialectMySQL, e: "expires_at < NOW()"},
+		{f: filter{Expired: &trueVal}, d: DialectSQLite, e: "expires_at < CURRENT_TIMESTAMP"},
+		{f: filter{}, e: ""},
+	}
+
+	for _, c := range cases {
+		q, v, e := ToSQL(c.f, WithDialect(c.d))
+		assert.Nil(t, e)
+		assert.Equal(t, c.e, q)
+		assert.Empty(t, v)
+	}
+
+	type wrongType struct {
+		Expired string `filter:"expires_at,op=before-now"`
+	}
+
+	_, _, e := ToSQL(wrongType{Expired: "yes"})
+	assert.ErrorContains(t, e, "expected bool; got string for operation before-now")
+}
+
+func TestEqOperatorBindsBool(t *testing.T) {
+	type filter struct {
+		Active *bool `filter:"active,op=eq"`
+	}
+
+	active := true
+	q, v, e := ToSQL(filter{Active: &active})
+	assert.Nil(t, e)
+	assert.Equal(t, "active = ?", q)
+	assert.Equal(t, []any{true}, v)
+}
+
+type tenantKey struct{}
+
+func TestContextOperator(t *testing.T) {
+	defer RestoreOperators(SnapshotOperators())
+
+	RegisterOperator("tenant", ContextOperator(func(ctx context.Context, c Clause) (string, []any, error) {
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		if !ok {
+			return "", nil, fmt.Errorf("missing tenant")
+		}
+
+		return "= ?", []any{tenant}, nil
+	}).Operator())
+
+	type filter struct {
+		Tenant bool    `filter:"tenant_id,op=tenant"`
+		Name   *string `filter:"name"`
+	}
+
+	name := "bobby"
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	q, v, e := ToSQLContext(ctx, filter{Tenant: true, Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "tenant_id = ? AND name = ?", q)
+	assert.Equal(t, []any{"acme", "bobby"}, v)
+
+	_, _, e = ToSQL(filter{Tenant: true})
+	assert.ErrorContains(t, e, "missing tenant")
+}
+
+func TestOptsOperator(t *testing.T) {
+	day := OptsOperator(func(opts Opts, c Clause) (string, []any, error) {
+		day := c.Val.(time.Time).Truncate(24 * time.Hour)
+		segment := "{col} >= ? AND {col} < ?"
+		if opts.ChainingStrategy == ChainingStrategyOr && !opts.WrapClauses {
+			segment = "(" + segment + ")"
+		}
+
+		return c.FullSegment(segment), []any{day, day.Add(24 * time.Hour)}, nil
+	}).Operator()
+
+	type filter struct {
+		Created *time.Time `filter:"created_at,op=day"`
+		Status  *string    `filter:"status"`
+	}
+
+	created, status := time.Date(2023, 5, 5, 12, 30, 0, 0, time.UTC), "todo"
+	start, end := time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC), time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC)
+	f := filter{Created: &created, Status: &status}
+
+	q, v, e := ToSQL(f, WithOperator("day", day), WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(t, "created_at >= $1 AND created_at < $2 AND status = $3", q)
+	assert.Equal(t, []any{start, end, "todo"}, v)
+
+	q, _, e = ToSQL(f, WithOperator("day", day), WithChainingStrategy(ChainingStrategyOr))
+	assert.Nil(t, e)
+	assert.Equal(t, "(created_at >= ? AND created_at < ?) OR status = ?", q)
+
+	q, _, e = ToSQL(f, WithOperator("day", day), WithChainingStrategy(ChainingStrategyOr), WithWrapClauses(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "(created_at >= ? AND created_at < ?) OR (status = ?)", q)
+}
+
+func TestRegisterOperatorConcurrently(t *testing.T) {
+	defer restoreRegistries(snapshotRegistries())
+
+	type filter struct {
+		Name string `filter:"name,op=concurrent-0"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("concurrent-%d", i)
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterOperator(name, SimpleOperator("= ?"))
+			RegisterDialectOperator(DialectPostgres, name, SimpleOperator("= ?"))
+		}()
+
+		go func() {
+			defer wg.Done()
+			_, _, _ = ToSQL(filter{Name: "bobby"}, WithDialect(DialectPostgres))
+		}()
+	}
+
+	wg.Wait()
+
+	q, _, e := ToSQL(filter{Name: "bobby"})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+}
+
+func TestBetweenOperatorArray(t *testing.T) {
+	type filter struct {
+		PriceRange *[2]float64 `filter:"price,op=between"`
+	}
+
+	q, v, e := ToSQL(filter{PriceRange: &[2]float64{10.5, 30}})
+	assert.Nil(t, e)
+	assert.Equal(t, "price BETWEEN ? AND ?", q)
+	assert.Equal(t, []any{10.5, float64(30)}, v)
+}
+
+func TestBetweenOperatorStruct(t *testing.T) {
+	type priceRange struct {
+		Lo, Hi float64
+	}
+
+	type filter struct {
+		PriceRange *priceRange `filter:"price,op=between"`
+		AgeRange   struct {
+			Min int
+			Max int
+		} `filter:"age,op=between"`
+	}
+
+	f := filter{PriceRange: &priceRange{Lo: 10.5, Hi: 30}}
+	f.AgeRange.Min, f.AgeRange.Max = 18, 65
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "price BETWEEN ? AND ? AND age BETWEEN ? AND ?", q)
+	assert.Equal(t, []any{10.5, float64(30), int64(18), int64(65)}, v)
+
+	// nil pointers are skipped
+	q, _, e = ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "age BETWEEN ? AND ?", q)
+}
+
+func TestBetweenOperatorStructFieldCount(t *testing.T) {
+	type filter struct {
+		Range struct {
+			Lo, Mid, Hi int
+		} `filter:"range,op=between"`
+	}
+
+	_, _, e := ToSQL(filter{})
+	assert.ErrorIs(t, e, ErrTypeMismatch)
+	assert.ErrorContains(t, e, "operation between expects a struct with two exported fields; got 3")
+}
+
+func TestRangeOperator(t *testing.T) {
+	type pointsRange struct {
+		Min, Max int
+	}
+
+	type filter struct {
+		Points *pointsRange `filter:"story_points,op=range"`
+		Prices []float64    `filter:"price,op=range"`
+	}
+
+	f := filter{Points: &pointsRange{Min: 3, Max: 8}, Prices: []float64{10.5, 30}}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "story_points >= ? AND story_points <= ? AND price >= ? AND price <= ?", q)
+	assert.Equal(t, []any{int64(3), int64(8), 10.5, float64(30)}, v)
+
+	q, _, e = ToSQL(f, WithChainingStrategy(ChainingStrategyOr))
+	assert.Nil(t, e)
+	assert.Equal(t, "(story_points >= ? AND story_points <= ?) OR (price >= ? AND price <= ?)", q)
+
+	_, _, e = ToSQL(filter{Prices: []float64{10.5}})
+	assert.ErrorContains(t, e, "operation range expects two elements in its slice")
+}
+
+func TestRangeOpenOperator(t *testing.T) {
+	type filter struct {
+		Created *[]*time.Time `filter:"created,op=range-open"`
+		Status  *string       `filter:"status"`
+	}
+
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+	status := "done"
+
+	q, v, e := ToSQL(filter{Created: &[]*time.Time{&from, &to}, Status: &status})
+	assert.Nil(t, e)
+	assert.Equal(t, "created >= ? AND created <= ? AND status = ?", q)
+	assert.Equal(t, []any{from, to, "done"}, v)
+
+	q, v, e = ToSQL(filter{Created: &[]*time.Time{&from, nil}})
+	assert.Nil(t, e)
+	assert.Equal(t, "created >= ?", q)
+	assert.Equal(t, []any{from}, v)
+
+	q, v, e = ToSQL(filter{Created: &[]*time.Time{nil, &to}}, WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(t, "created <= $1", q)
+	assert.Equal(t, []any{to}, v)
+
+	q, v, e = ToSQL(filter{Created: &[]*time.Time{nil, nil}, Status: &status})
+	assert.Nil(t, e)
+	assert.Equal(t, "status = ?", q)
+	assert.Equal(t, []any{"done"}, v)
+
+	q, _, e = ToSQL(filter{Created: &[]*time.Time{&from, &to}, Status: &status}, WithChainingStrategy(ChainingStrategyOr))
+	assert.Nil(t, e)
+	assert.Equal(t, "(created >= ? AND created <= ?) OR status = ?", q)
+
+	_, _, e = ToSQL(filter{Created: &[]*time.Time{&from}})
+	assert.ErrorIs(t, e, ErrTypeMismatch)
+	assert.ErrorContains(t, e, "operation range-open expects two elements in its slice")
+}
+
+func TestIeqOperator(t *testing.T) {
+	type filter struct {
+		Email *string `filter:"email,op=ieq"`
+	}
+
+	email := "Bobby@Example.com"
+	q, v, e := ToSQL(filter{Email: &email})
+	assert.Nil(t, e)
+	assert.Equal(t, "LOWER(email) = LOWER(?)", q)
+	assert.Equal(t, []any{"Bobby@Example.com"}, v)
+
+	type wrongType struct {
+		Email int `filter:"email,op=ieq"`
+	}
+
+	_, _, e = ToSQL(wrongType{Email: 1})
+	assert.ErrorContains(t, e, "expected string; got int for operation ieq")
+}
+
+func TestIinOperator(t *testing.T) {
+	type filter struct {
+		Statuses []string `filter:"status,op=iin"`
+	}
+
+	q, v, e := ToSQL(filter{Statuses: []string{"Todo", "DONE"}}, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "LOWER(status) IN(LOWER($1),LOWER($2))", q)
+	assert.Equal(t, []any{"Todo", "DONE"}, v)
+
+	q, _, e = ToSQL(filter{Statuses: []string{"a", "b", "c"}}, WithInChunkSize(2))
+	assert.Nil(t, e)
+	assert.Equal(t, "(LOWER(status) IN(LOWER(?),LOWER(?)) OR LOWER(status) IN(LOWER(?)))", q)
+
+	// empty slices follow the empty in behavior
+	q, _, e = ToSQL(filter{Statuses: []string{}})
+	assert.Nil(t, e)
+	assert.Equal(t, "LOWER(status) IN(NULL)", q)
+
+	q, _, e = ToSQL(filter{Statuses: []string{}}, WithEmptyInBehavior(EmptyInLogical))
+	assert.Nil(t, e)
+	assert.Equal(t, "1=0", q)
+
+	type wrongType struct {
+		Status string `filter:"status,op=iin"`
+	}
+
+	_, _, e = ToSQL(wrongType{Status: "todo"})
+	assert.ErrorContains(t, e, "expected slice or array; got string for operation iin")
+}
+
+func TestExistsOperator(t *testing.T) {
+	type filter struct {
+		HasOrders  *string   `filter:"orders,op=exists"`
+		HasInvoice *Subquery `filter:"invoices,op=exists"`
+		Age        *int      `filter:"age,op=gt"`
+	}
+
+	orders := "SELECT 1 FROM orders WHERE orders.user_id = users.id"
+	invoice := Subquery{
+		Query: "SELECT 1 FROM invoices WHERE invoices.user_id = users.id AND invoices.total > ?",
+		Args:  []any{100},
+	}
+	age := 18
+
+	q, v, e := ToSQL(filter{HasOrders: &orders, HasInvoice: &invoice, Age: &age}, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id) AND "+
+		"EXISTS (SELECT 1 FROM invoices WHERE invoices.user_id = users.id AND invoices.total > $1) AND age > $2", q)
+	assert.Equal(t, []any{100, int64(18)}, v)
+
+	q, v, e = ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+
+	type wrongType struct {
+		HasOrders int `filter:"orders,op=exists"`
+	}
+
+	_, _, e = ToSQL(wrongType{HasOrders: 1})
+	assert.ErrorContains(t, e, "expected string or struct; got int for operation exists")
+
+	type wrongStruct struct {
+		HasOrders struct{ Query string } `filter:"orders,op=exists"`
+	}
+
+	_, _, e = ToSQL(wrongStruct{})
+	assert.ErrorContains(t, e, "for operation exists")
+}
+
+func TestExistsOperatorWithEscapedQuestionmark(t *testing.T) {
+	type orderFilter struct {
+		HasKey *string `filter:"meta,op=has-key"`
+		Total  *int    `filter:"total,op=gt"`
+	}
+
+	type filter struct {
+		HasOrders *Subquery `filter:"orders,op=exists"`
+	}
+
+	hasKey := WithOperator("has-key", SimpleOperator("?? ?"))
+	key, total := "gift", 100
+
+	where, args, e := ToSQL(orderFilter{HasKey: &key, Total: &total}, hasKey)
+	assert.Nil(t, e)
+	assert.Equal(t, "meta ?? ? AND total > ?", where)
+	assert.Equal(t, 2, CountPlaceholders(where))
+
+	s, e := Interpolate(where, args)
+	assert.Nil(t, e)
+	assert.Equal(t, "meta ? 'gift' AND total > 100", s)
+
+	subquery := Subquery{Query: "SELECT 1 FROM orders WHERE " + where, Args: args}
+	q, v, e := ToSQL(filter{HasOrders: &subquery}, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "EXISTS (SELECT 1 FROM orders WHERE meta ? $1 AND total > $2)", q)
+	assert.Equal(t, []any{"gift", int64(100)}, v)
+}
+
+func TestSubqueryKeepsColumnToken(t *testing.T) {
+	defer RestoreOperators(SnapshotOperators())
+	RegisterOperator("not-exists", Not(Operators["exists"]))
+
+	type inner struct {
+		Tagged *Subquery `filter:"tags,op=exists"`
+	}
+
+	type filter struct {
+		HasOrders  *Subquery `filter:"orders,op=exists"`
+		NoInvoices *string   `filter:"invoices,op=not-exists"`
+		Group      inner     `filter:",group"`
+	}
+
+	orders := Subquery{Query: "SELECT 1 FROM orders WHERE note = '{col}'"}
+	invoices := "SELECT 1 FROM invoices WHERE note = '{col}'"
+	tags := Subquery{Query: "SELECT '{col}'"}
+
+	q, _, e := ToSQL(filter{HasOrders: &orders, NoInvoices: &invoices, Group: inner{Tagged: &tags}})
+	assert.Nil(t, e)
+	assert.Equal(t, "EXISTS (SELECT 1 FROM orders WHERE note = '{col}') AND "+
+		"NOT (EXISTS (SELECT 1 FROM invoices WHERE note = '{col}')) AND (EXISTS (SELECT '{col}'))", q)
+}
+
+func TestSubqueryArgsBoundOnce(t *testing.T) {
+	type orderFilter struct {
+		Status *string `filter:"status"`
+	}
+
+	type filter struct {
+		HasOrders *Subquery `filter:"orders,op=exists"`
+		Expr      *Raw      `filter:"total,op=raw"`
+	}
+
+	var cols []string
+	transform := WithValueTransformer(func(col string, v any) any {
+		cols = append(cols, col)
+		if s, ok := v.(string); ok {
+			return s + "!!"
+		}
+		return v
+	})
+
+	status := "todo"
+	where, args, e := ToSQL(orderFilter{Status: &status}, transform)
+	assert.Nil(t, e)
+	assert.Equal(t, []any{"todo!!"}, args)
+
+	subquery := Subquery{Query: "SELECT 1 FROM orders WHERE " + where, Args: args}
+	raw := Raw{SQL: "> ?", Args: []any{"100"}}
+	_, v, e := ToSQL(filter{HasOrders: &subquery, Expr: &raw}, transform)
+	assert.Nil(t, e)
+	assert.Equal(t, []any{"todo!!", "100"}, v)
+	assert.Equal(t, []string{"status"}, cols)
+}
+
+func TestSubqueryComparisonOperators(t *testing.T) {
+	type filter struct {
+		AbovePrices *Subquery `filter:"price,op=gt-all"`
+		BelowPrices *string   `filter:"price,op=lt-all"`
+		Category    *Subquery `filter:"category_id,op=eq-any"`
+	}
+
+	above := Subquery{Query: "SELECT price FROM products WHERE brand = ?", Args: []any{"acme"}}
+	below := "SELECT price FROM premium_products"
+	category := Subquery{Query: "SELECT id FROM categories WHERE active = ?", Args: []any{true}}
+
+	q, v, e := ToSQL(filter{AbovePrices: &above, BelowPrices: &below, Category: &category},
+		WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "price > ALL (SELECT price FROM products WHERE brand = $1) AND "+
+		"price < ALL (SELECT price FROM premium_products) AND "+
+		"category_id = ANY (SELECT id FROM categories WHERE active = $2)", q)
+	assert.Equal(t, []any{"acme", true}, v)
+
+	d, e := DescribeText(filter{BelowPrices: &below})
+	assert.Nil(t, e)
+	assert.Equal(t, "price is less than all of (SELECT price FROM premium_products)", d)
+
+	q, v, e = ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+
+	type wrongType struct {
+		Price int `filter:"price,op=gt-all"`
+	}
+
+	_, _, e = ToSQL(wrongType{Price: 1})
+	assert.ErrorContains(t, e, "expected string or struct; got int for operation gt-all")
+}
+
+// registries holds a copy of the global registries operators write to, see snapshotRegistries.
+type registries struct {
+	operators  map[string]Operator
+	dialects   map[Dialect]map[string]Operator
+	describers map[string]Describer
+}
+
+// snapshotRegistries works like SnapshotOperators, but includes the dialect variants and describers,
+// for tests registering those (eg: using AliasOperator). Pass it to restoreRegistries using defer.
+func snapshotRegistries() registries {
+	snapshot := registries{
+		operators:  SnapshotOperators(),
+		dialects:   map[Dialect]map[string]Operator{},
+		describers: map[string]Describer{},
+	}
+
+	operatorsMu.RLock()
+	for d, variants := range dialectOperators {
+		snapshot.dialects[d] = map[string]Operator{}
+		for name, op := range variants {
+			snapshot.dialects[d][name] = op
+		}
+	}
+	operatorsMu.RUnlock()
+
+	describersMu.RLock()
+	for name, d := range Describers {
+		snapshot.describers[name] = d
+	}
+	describersMu.RUnlock()
+
+	return snapshot
+}
+
+// restoreRegistries replaces the global registries with the snapshot, see snapshotRegistries.
+func restoreRegistries(snapshot registries) {
+	RestoreOperators(snapshot.operators)
+
+	operatorsMu.Lock()
+	for d := range dialectOperators {
+		delete(dialectOperators, d)
+	}
+	for d, variants := range snapshot.dialects {
+		dialectOperators[d] = variants
+	}
+	operatorsMu.Unlock()
+
+	describersMu.Lock()
+	for name := range Describers {
+		delete(Describers, name)
+	}
+	for name, d := range snapshot.describers {
+		Describers[name] = d
+	}
+	describersMu.Unlock()
+}
+
+func TestSnapshotRestoreOperators(t *testing.T) {
+	snapshot := SnapshotOperators()
+
+	RegisterOperator("snapshot-test", SimpleOperator("= ?"))
+	RegisterOperator("eq", SimpleOperator("== ?"))
+	assert.Contains(t, Operators, "snapshot-test")
+	assert.NotContains(t, snapshot, "snapshot-test")
+
+	RestoreOperators(snapshot)
+	assert.NotContains(t, Operators, "snapshot-test")
+	assert.Len(t, Operators, len(snapshot))
+
+	type filter struct {
+		Name *string `filter:"name,op=eq"`
+	}
+
+	name := "jane"
+	q, _, e := ToSQL(filter{Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+
+	// the test helper covers the dialect variants and describers an alias copies as well
+	registries := snapshotRegistries()
+	assert.Nil(t, AliasOperator("snapshot-alias", "regexp"))
+	restoreRegistries(registries)
+	assert.NotContains(t, Operators, "snapshot-alias")
+	assert.NotContains(t, dialectOperators[DialectPostgres], "snapshot-alias")
+	assert.NotContains(t, Describers, "snapshot-alias")
+}
+
+func TestJSONPathOperator(t *testing.T) {
+	type filter struct {
+		Country *string `filter:"metadata,op=json-path,path=country"`
+		City    *string `filter:"metadata,op=json-path,path=address.city"`
+	}
+
+	country, city := "NL", "Amsterdam"
+	f := filter{Country: &country, City: &city}
+
+	cases := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{
+			expected: "JSON_EXTRACT(metadata, '$.country') = ? AND JSON_EXTRACT(metadata, '$.address.city') = ?",
+		},
+		{
+			dialect:  DialectSQLite,
+			expected: "JSON_EXTRACT(metadata, '$.country') = ? AND JSON_EXTRACT(metadata, '$.address.city') = ?",
+		},
+		{
+			dialect: DialectMySQL,
+			expected: "JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.country')) = ? AND " +
+				"JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.address.city')) = ?",
+		},
+		{
+			dialect:  DialectPostgres,
+			expected: "metadata->>'country' = $1 AND metadata->'address'->>'city' = $2",
+		},
+		{
+			dialect:  DialectSQLServer,
+			expected: "JSON_VALUE(metadata, '$.country') = @p1 AND JSON_VALUE(metadata, '$.address.city') = @p2",
+		},
+	}
+
+	for _, tc := range cases {
+		q, v, e := ToSQL(f, WithDialect(tc.dialect))
+		assert.Nil(t, e)
+		assert.Equal(t, tc.expected, q)
+		assert.Equal(t, []any{country, city}, v)
+	}
+
+	type missingPath struct {
+		Country *string `filter:"metadata,op=json-path"`
+	}
+
+	_, _, e := ToSQL(missingPath{Country: &country})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "operation json-path expects a path")
+
+	type invalidPath struct {
+		Country *string `filter:"metadata,op=json-path,path=country') = 1 OR ('"`
+	}
+
+	_, _, e = ToSQL(invalidPath{Country: &country})
+	assert.ErrorContains(t, e, "operation json-path: invalid path")
+}
+
+func TestColumnComparisonOperators(t *testing.T) {
+	type filter struct {
+		UpdatedAfter *string `filter:"updated_at,op=gt-col"`
+		SameAuthor   *string `filter:"author_id,op=eq-col"`
+		MinAge       *int    `filter:"age,op=gte"`
+	}
+
+	updated, author, age := "created_at", "users.id", 18
+	f := filter{UpdatedAfter: &updated, SameAuthor: &author, MinAge: &age}
+
+	q, v, e := ToSQL(f, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "updated_at > created_at AND author_id = users.id AND age >= $1", q)
+	assert.Equal(t, []any{int64(18)}, v)
+
+	d, e := DescribeText(filter{UpdatedAfter: &updated})
+	assert.Nil(t, e)
+	assert.Equal(t, "updated_at is greater than created_at", d)
+
+	injection := "created_at OR 1=1"
+	_, _, e = ToSQL(filter{UpdatedAfter: &injection})
+	assert.ErrorIs(t, e, ErrInvalidColumn)
+
+	_, _, e = ToSQL(f, WithAllowedColumns("updated_at", "author_id", "age", "created_at"))
+	assert.ErrorIs(t, e, ErrColumnNotAllowed)
+	assert.ErrorContains(t, e, `"users.id"`)
+
+	type wrongType struct {
+		UpdatedAfter int `filter:"updated_at,op=gt-col"`
+	}
+
+	_, _, e = ToSQL(wrongType{UpdatedAfter: 1})
+	assert.ErrorContains(t, e, "expected string; got int for operation gt-col")
+}
+
+func TestTimeComparisonAliases(t *testing.T) {
+	type filter struct {
+		DueBefore      *time.Time `filter:"due_date,op=before"`
+		CreatedAfter   *time.Time `filter:"created_at,op=after"`
+		StartOnOrAfter *time.Time `filter:"starts_at,op=on-or-after"`
+		EndOnOrBefore  *time.Time `filter:"ends_at,op=on-or-before"`
+	}
+
+	day := time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC)
+	f := filter{DueBefore: &day, CreatedAfter: &day, StartOnOrAfter: &day, EndOnOrBefore: &day}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "due_date < ? AND created_at > ? AND starts_at >= ? AND ends_at <= ?", q)
+	assert.Equal(t, []any{day, day, day, day}, v)
+
+	d, e := DescribeText(filter{DueBefore: &day, StartOnOrAfter: &day})
+	assert.Nil(t, e)
+	assert.Equal(t, "due_date is before 2023-05-05T00:00:00Z and starts_at is on or after 2023-05-05T00:00:00Z", d)
+}
+
+func TestLengthOperators(t *testing.T) {
+	type filter struct {
+		MinTags *int  `filter:"tags,op=length-gte"`
+		MaxTags *int  `filter:"tags,op=length-lte"`
+		Labels  *uint `filter:"labels,op=length-eq"`
+	}
+
+	minTags, maxTags, labels := 1, 5, uint(2)
+	f := filter{MinTags: &minTags, MaxTags: &maxTags, Labels: &labels}
+
+	cases := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{
+			expected: "json_array_length(tags) >= ? AND json_array_length(tags) <= ? AND json_array_length(labels) = ?",
+		},
+		{
+			dialect:  DialectSQLite,
+			expected: "json_array_length(tags) >= ? AND json_array_length(tags) <= ? AND json_array_length(labels) = ?",
+		},
+		{
+			dialect:  DialectPostgres,
+			expected: "jsonb_array_length(tags) >= $1 AND jsonb_array_length(tags) <= $2 AND jsonb_array_length(labels) = $3",
+		},
+		{
+			dialect:  DialectMySQL,
+			expected: "JSON_LENGTH(tags) >= ? AND JSON_LENGTH(tags) <= ? AND JSON_LENGTH(labels) = ?",
+		},
+		{
+			dialect: DialectSQLServer,
+			expected: "(SELECT COUNT(*) FROM OPENJSON(tags)) >= @p1 AND (SELECT COUNT(*) FROM OPENJSON(tags)) <= @p2 AND " +
+				"(SELECT COUNT(*) FROM OPENJSON(labels)) = @p3",
+		},
+	}
+
+	for _, tc := range cases {
+		q, v, e := ToSQL(f, WithDialect(tc.dialect))
+		assert.Nil(t, e)
+		assert.Equal(t, tc.expected, q)
+		assert.Equal(t, []any{int64(1), int64(5), uint64(2)}, v)
+	}
+
+	type wrongType struct {
+		MinTags string `filter:"tags,op=length-gte"`
+	}
+
+	_, _, e := ToSQL(wrongType{MinTags: "1"})
+	assert.ErrorContains(t, e, "for operation length-gte")
+}
+
+func TestGroupsOperator(t *testing.T) {
+	type condition struct {
+		Status    *string `filter:"status"`
+		MinPoints *int    `filter:"story_points,op=gte"`
+	}
+
+	type filter struct {
+		Title      *string      `filter:"title"`
+		Conditions []condition  `filter:"conditions,op=groups"`
+		Pointers   []*condition `filter:"pointers,op=groups"`
+	}
+
+	title, todo, doing, two := "review", "todo", "doing", 2
+	f := filter{
+		Title: &title,
+		Conditions: []condition{
+			{Status: &todo, MinPoints: &two},
+			{Status: &doing},
+		},
+	}
+
+	q, v, e := ToSQL(f, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "title = $1 AND ((status = $2 AND story_points >= $3) OR (status = $4))", q)
+	assert.Equal(t, []any{title, todo, int64(2), doing}, v)
+
+	// the groups are joined using AND regardless of the chaining strategy
+	q, _, e = ToSQL(f, WithChainingStrategy(ChainingStrategyOr))
+	assert.Nil(t, e)
+	assert.Equal(t, "title = ? OR ((status = ? AND story_points >= ?) OR (status = ?))", q)
+
+	q, v, e = ToSQL(filter{Pointers: []*condition{{Status: &todo}}})
+	assert.Nil(t, e)
+	assert.Equal(t, "((status = ?))", q)
+	assert.Equal(t, []any{todo}, v)
+
+	// empty slices are skipped
+	q, v, e = ToSQL(filter{Title: &title, Conditions: []condition{}})
+	assert.Nil(t, e)
+	assert.Equal(t, "title = ?", q)
+	assert.Equal(t, []any{title}, v)
+
+	// a group without any clauses matches all rows
+	q, v, e = ToSQL(filter{Conditions: []condition{{Status: &todo}, {}}})
+	assert.Nil(t, e)
+	assert.Equal(t, "1=1", q)
+	assert.Empty(t, v)
+
+	type invalidCondition struct {
+		Status *string `filter:"status,op=unknown"`
+	}
+
+	type invalidFilter struct {
+		Conditions []invalidCondition `filter:"conditions,op=groups"`
+	}
+
+	_, _, e = ToSQL(invalidFilter{Conditions: []invalidCondition{{Status: &todo}}})
+	assert.ErrorIs(t, e, ErrUnknownOperator)
+	assert.ErrorContains(t, e, "field Conditions (column conditions): group 0: field Status (column status)")
+}
+
+func TestRawOperator(t *testing.T) {
+	type filter struct {
+		Points *Raw    `filter:"story_points,op=raw"`
+		Due    *Raw    `filter:"due_date,op=raw"`
+		Name   *string `filter:"name"`
+	}
+
+	name := "jane"
+	f := filter{
+		Points: &Raw{SQL: "BETWEEN ? AND ? + 2", Args: []any{3, 5}},
+		Due:    &Raw{SQL: "COALESCE({col}, created_at) < ?", Args: []any{"2023-05-05"}},
+		Name:   &name,
+	}
+
+	q, v, e := ToSQL(f, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "story_points BETWEEN $1 AND $2 + 2 AND COALESCE(due_date, created_at) < $3 AND name = $4", q)
+	assert.Equal(t, []any{3, 5, "2023-05-05", name}, v)
+
+	q, _, e = ToSQL(filter{Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+
+	type wrongType struct {
+		Points string `filter:"story_points,op=raw"`
+	}
+
+	_, _, e = ToSQL(wrongType{Points: "> 1"})
+	assert.ErrorIs(t, e, ErrTypeMismatch)
+	assert.ErrorContains(t, e, "expected Raw; got string for operation raw")
+}
+
+func TestAliasOperator(t *testing.T) {
+	defer restoreRegistries(snapshotRegistries())
+
+	assert.Nil(t, AliasOperator("matches", "regexp"))
+	assert.Contains(t, Describers, "matches")
+
+	type filter struct {
+		Name *string `filter:"name,op=matches"`
+	}
+
+	name := "^j"
+	q, v, e := ToSQL(filter{Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "name REGEXP ?", q)
+	assert.Equal(t, []any{name}, v)
+
+	q, _, e = ToSQL(filter{Name: &name}, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "name ~ $1", q)
+
+	// resolved at registration, re-registering the target doesn't affect the alias
+	RegisterOperator("regexp", SimpleOperator("LIKE ?"))
+	q, _, e = ToSQL(filter{Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "name REGEXP ?", q)
+
+	e = AliasOperator("alias", "unknown")
+	assert.ErrorIs(t, e, ErrUnknownOperator)
+	assert.NotContains(t, Operators, "alias")
+}
+
+func TestAliasOperatorComposite(t *testing.T) {
+	defer restoreRegistries(snapshotRegistries())
+
+	assert.Nil(t, AliasOperator("within", "between"))
+	RegisterOperator("not-within", Not(Operators["between"]))
+
+	type priceRange struct {
+		Lo, Hi float64
+	}
+
+	type filter struct {
+		Price    *priceRange `filter:"price,op=within"`
+		NotPrice *priceRange `filter:"price,op=not-within"`
+		Points   *Raw        `filter:"points,op=myraw"`
+	}
+
+	f := filter{
+		Price:    &priceRange{Lo: 10, Hi: 20},
+		NotPrice: &priceRange{Lo: 12, Hi: 14},
+		Points:   &Raw{SQL: "> ?", Args: []any{1}},
+	}
+
+	q, v, e := ToSQL(f, WithOperator("myraw", Operators["raw"]))
+	assert.Nil(t, e)
+	assert.Equal(t, "price BETWEEN ? AND ? AND NOT (price BETWEEN ? AND ?) AND points > ?", q)
+	assert.Equal(t, []any{float64(10), float64(20), float64(12), float64(14), 1}, v)
+
+	// operators that don't interpret structs themselves still reject them
+	RegisterOperator("within", SimpleOperator("= ?"))
+	_, _, e = ToSQL(filter{Price: &priceRange{Lo: 10, Hi: 20}})
+	assert.ErrorIs(t, e, ErrUnsupportedType)
+	assert.ErrorContains(t, e, "field Price (column price): unsupported type: structs are not supported")
+
+	RegisterOperator("not-within", Not(SimpleOperator("= ?")))
+	_, _, e = ToSQL(filter{NotPrice: &priceRange{Lo: 10, Hi: 20}})
+	assert.ErrorIs(t, e, ErrUnsupportedType)
+}
+
+func TestAliasOperatorWhileDescribing(t *testing.T) {
+	defer restoreRegistries(snapshotRegistries())
+
+	type filter struct {
+		Name string `filter:"name,op=regexp"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		alias := fmt.Sprintf("describing-%d", i)
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, AliasOperator(alias, "regexp"))
+		}()
+
+		go func() {
+			defer wg.Done()
+			_, err := DescribeText(filter{Name: "^j"})
+			assert.Nil(t, err)
+		}()
+	}
+
+	wg.Wait()
+}
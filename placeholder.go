@@ -1,7 +1,7 @@
 package queryfilter
 
 import (
-	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -10,6 +10,15 @@ import (
 //
 // note that these placeholders are internal only and will be replaced by the placeholders
 // configured by the PlaceholderStrategy when calling ToSQL.
+//
+// Only the questionmark is rewritten, so operators are free to emit already formatted
+// placeholders (eg: `$99` for a positional subquery parameter) which are left untouched.
+// When an operator needs a literal questionmark in the query (eg: the jsonb `?` operator in PostgreSQL)
+// it should be escaped by doubling it (`??`). The escape is resolved wherever the placeholders are resolved:
+// the numbered (and named) placeholder strategies and Interpolate write it as a single `?`, and CountPlaceholders
+// doesn't count it. PlaceholderStrategyQuestionmark leaves the query as is, keeping the escape, so its result
+// composes into a Subquery, a fragment passed to CountPlaceholders or a query builder like squirrel
+// (which uses the same escape) without the literal questionmark turning into a placeholder.
 func PlaceholderList(n int) string {
 	if n == 0 || n == 1 {
 		return "?"
@@ -18,46 +27,84 @@ func PlaceholderList(n int) string {
 	return strings.Repeat(",?", n)[1:]
 }
 
-type replacerFn = func(int) string
+// replacerFn writes the placeholder for the n-th value to the builder.
+type replacerFn = func(b *strings.Builder, n int)
 
 func makeReplacer(prefix string) replacerFn {
-	return func(i int) string {
-		return fmt.Sprintf("%s%d", prefix, i)
+	return func(b *strings.Builder, n int) {
+		var digits [20]byte
+		b.WriteString(prefix)
+		b.Write(strconv.AppendInt(digits[:0], int64(n), 10))
 	}
 }
 
 var (
-	defaultReplacer = func(_ int) string { return "?" }
-	dollarReplacer  = makeReplacer("$")
-	colonReplacer   = makeReplacer(":")
+	dollarReplacer = makeReplacer("$")
+	colonReplacer  = makeReplacer(":")
+	atReplacer     = makeReplacer("@p")
 )
 
+// estimatedPlaceholderSize is the number of bytes reserved per placeholder when rewriting a query,
+// which fits a prefix and a number of a few digits (eg: `@p123`).
+const estimatedPlaceholderSize = 6
+
 func replace(q string, placeholderNumberOffset int, fn replacerFn) string {
 	var (
-		readerOffset = 0
-		b            strings.Builder
-		n            = placeholderNumberOffset
+		b      strings.Builder
+		n      = placeholderNumberOffset
+		offset = 0
 	)
 
-	for {
-		// look for first ? in offsetted string
-		idx := strings.Index(q[readerOffset:], "?")
-		if idx < 0 {
-			// write the rest of the original query before breaking
-			b.WriteString(q[readerOffset:])
-			break
+	b.Grow(len(q) + strings.Count(q, "?")*estimatedPlaceholderSize)
+
+	for i := 0; i < len(q); i++ {
+		if q[i] != '?' {
+			continue
 		}
 
-		// grab the chunk from last offset to new ?
-		chunk := q[readerOffset:][:idx]
+		// write the chunk from the last offset up to the ?
+		b.WriteString(q[offset:i])
 
-		// increment offset and write new placeholder
-		readerOffset += idx + 1
-		b.WriteString(chunk)
-		b.WriteString(fn(n))
+		// an escaped questionmark (??) is written as a literal ? and
+		// doesn't count as a placeholder
+		if i+1 < len(q) && q[i+1] == '?' {
+			b.WriteByte('?')
+			i++
+			offset = i + 1
+			continue
+		}
 
+		fn(&b, n)
+		offset = i + 1
 		n++
 	}
 
+	// write the rest of the original query
+	b.WriteString(q[offset:])
+
 	return b.String()
 }
+
+// CountPlaceholders returns the number of placeholders (?) in the query fragment, before they're rewritten
+// according to the PlaceholderStrategy. Escaped questionmarks (??) are not counted, see PlaceholderList.
+//
+// This allows computing the offset of the placeholders of a subsequent fragment (see WithPlaceholderOffset)
+// when composing queries, as the number of args doesn't necessarily match the number of placeholders.
+func CountPlaceholders(fragment string) int {
+	n := 0
+	for i := 0; i < len(fragment); i++ {
+		if fragment[i] != '?' {
+			continue
+		}
+
+		// skip the escaped questionmark
+		if i+1 < len(fragment) && fragment[i+1] == '?' {
+			i++
+			continue
+		}
+
+		n++
+	}
+
+	return n
+}
@@ -1,6 +1,8 @@
 package queryfilter
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,12 +32,11 @@ func TestReplace_WithOffset(t *testing.T) {
 	assert.Equal(t, e, q)
 }
 
-func TestReplace_DefaultReplacer(t *testing.T) {
-	query := "name = ? AND color = ?"
-	q := replace(query, 0, defaultReplacer)
-	e := "name = ? AND color = ?"
+func TestApplyPlaceholders_Questionmark(t *testing.T) {
+	query := "name = ? AND meta ?? ?"
+	q := applyPlaceholders(query, DefaultOpts())
 
-	assert.Equal(t, e, q)
+	assert.Equal(t, query, q)
 }
 
 func TestReplace_ColonReplacer(t *testing.T) {
@@ -62,3 +63,104 @@ func TestPlaceholderList(t *testing.T) {
 		assert.Equal(t, tc.expect, PlaceholderList(tc.num))
 	}
 }
+
+func TestReplace_EscapedQuestionmark(t *testing.T) {
+	query := "meta ?? ? AND name = ?"
+	q := replace(query, 1, dollarReplacer)
+	e := "meta ? $1 AND name = $2"
+
+	assert.Equal(t, e, q)
+}
+
+func TestReplace_PreformattedPlaceholders(t *testing.T) {
+	Operators["preformatted"] = func(c Clause) (string, []any, error) {
+		return "= $99", []any{c.Val}, nil
+	}
+	defer delete(Operators, "preformatted")
+
+	type filter struct {
+		Name string `filter:"name"`
+		ID   int    `filter:"id,op=preformatted"`
+	}
+
+	q, _, err := ToSQL(filter{Name: "bobby", ID: 1}, WithPlaceholderStrategy(PlaceholderStrategyDollar))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "name = $1 AND id = $99", q)
+}
+
+func TestReplace_AtReplacer(t *testing.T) {
+	query := "name = ? AND color = ?"
+	q := replace(query, 1, atReplacer)
+	e := "name = @p1 AND color = @p2"
+
+	assert.Equal(t, e, q)
+}
+
+func BenchmarkReplace(b *testing.B) {
+	cases := []struct {
+		name string
+		n    int
+	}{
+		{"10", 10},
+		{"100", 100},
+		{"1000", 1000},
+	}
+
+	for _, bc := range cases {
+		query := fmt.Sprintf("color IN(%s) AND name = ?", PlaceholderList(bc.n))
+
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				replace(query, 1, dollarReplacer)
+			}
+		})
+	}
+}
+
+func TestReplace_TrailingQuestionmark(t *testing.T) {
+	assert.Equal(t, "a = $1", replace("a = ?", 1, dollarReplacer))
+	assert.Equal(t, "a ? b = $1", replace("a ?? b = ?", 1, dollarReplacer))
+	assert.Equal(t, "$1", replace("?", 1, dollarReplacer))
+	assert.Equal(t, "?", replace("??", 1, dollarReplacer))
+	assert.Equal(t, "?$1", replace("???", 1, dollarReplacer))
+}
+
+func TestCountPlaceholders(t *testing.T) {
+	table := []struct {
+		fragment string
+		expect   int
+	}{
+		{"", 0},
+		{"title IS NULL", 0},
+		{"name = ?", 1},
+		{"color IN(?,?,?) AND age > ?", 4},
+		{"tags ?? ?", 1},
+		{"???", 1},
+	}
+
+	for _, tc := range table {
+		assert.Equal(t, tc.expect, CountPlaceholders(tc.fragment), tc.fragment)
+	}
+}
+
+func FuzzReplace(f *testing.F) {
+	for _, seed := range []string{"", "?", "??", "???", "a = ?", "a ?? b = ?", "x IN(?,?,?)", "?a?"} {
+		f.Add(seed, 1)
+	}
+
+	f.Fuzz(func(t *testing.T, q string, offset int) {
+		out := replace(q, offset, dollarReplacer)
+
+		// a lone questionmark becomes a placeholder, an escaped one is written once
+		escaped := strings.Count(q, "??")
+		lone := strings.Count(q, "?") - 2*escaped
+		assert.Equal(t, escaped, strings.Count(out, "?"))
+		assert.Equal(t, lone, strings.Count(out, "$")-strings.Count(q, "$"))
+		assert.Equal(t, lone, CountPlaceholders(q))
+
+		// the questionmark strategy leaves the query as is, including the escapes
+		assert.Equal(t, q, applyPlaceholders(q, DefaultOpts()))
+	})
+}
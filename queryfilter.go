@@ -1,8 +1,13 @@
 package queryfilter
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,6 +22,11 @@ const (
 	ChainingStrategyAnd ChainingStrategy = "AND"
 )
 
+// valid reports whether the chaining strategy is one of the supported strategies.
+func (s ChainingStrategy) valid() bool {
+	return s == ChainingStrategyAnd || s == ChainingStrategyOr
+}
+
 // PlaceholderStrategy defines how placeholders are defined in the resulting querystring.
 // for example working with MySQL based databases you would want to use PlaceholderStrategyQuestionmark
 // as the resulting querystring would include a single questionmark as a placeholder
@@ -25,6 +35,7 @@ type PlaceholderStrategy int
 const (
 	// PlaceholderStrategyQuestionmark will insert a single questionmark as a placeholder.
 	// this option is best suited for MySQL / MariaDB / SQLite databases.
+	// Escaped questionmarks (??) are kept as is, see PlaceholderList.
 	PlaceholderStrategyQuestionmark PlaceholderStrategy = iota
 
 	// PlaceholderStrategyColon will insert a positional placeholder using a colon (:1, :2, etc)
@@ -33,16 +44,59 @@ const (
 	// PlaceholderStrategyDollar will insert a positional placeholder using a dollar sign ($1, $2, etc).
 	// Most commonly used with PostgreSQL databases.
 	PlaceholderStrategyDollar
+
+	// PlaceholderStrategyAt will insert a positional placeholder using an at sign (@p1, @p2, etc).
+	// Most commonly used with SQL Server databases.
+	PlaceholderStrategyAt
+)
+
+// EmptyInBehavior defines the query segment the `in` and `not-in` operators result in for an empty slice.
+type EmptyInBehavior int
+
+const (
+	// EmptyInNull compares the column against NULL, resulting in `IN(NULL)` and `NOT IN(NULL)`.
+	// Note that neither matches any rows, as comparing against NULL is never true. This means
+	// excluding an empty list using `not-in` filters out all rows, rather than none.
+	EmptyInNull EmptyInBehavior = iota
+
+	// EmptyInLogical treats the empty slice as an empty set: `in` matches no rows (`1=0`)
+	// and `not-in` matches all rows (`1=1`).
+	EmptyInLogical
 )
 
+// skipTag is the tag value used to explicitly exclude a field from the filter.
+const skipTag = "-"
+
+// tagFlags lists the segments a tag can hold without a value, eg: `LOWER(email),op=eq,raw-col`.
+var tagFlags = map[string]bool{
+	// raw-col emits the column as is, bypassing the identifier check of WithStrictColumns
+	"raw-col": true,
+
+	// group renders the clauses of the (embedded) struct as a parenthesized group, negate wraps it in NOT
+	"group":  true,
+	"negate": true,
+
+	// nil-is-null compares nil values using IS NULL rather than skipping them, see WithNilAsIsNull
+	"nil-is-null": true,
+}
+
+// identifierPattern matches safe (optionally qualified) column names, eg: `age` or `users.age`.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
 var (
 	// TagName defines the struct tag we look for in the structs we're parsing,
 	// eg: the `filter` in `filter:"name,op=eq"`. It can be configured by setting
-	// `queryFilter.TagName`, eg: `queryFilter.TagName = "qf"` to the value you desire.
+	// `queryFilter.TagName`, eg: `queryFilter.TagName = "qf"` to the value you desire,
+	// or on an individual basis when calling `ToSQL` using WithTagName.
+	//
+	// Similar to `json:"-"`, a field tagged with `filter:"-"` is never included in the query.
 	TagName = "filter"
 
 	// Operators is a globally defined map of available operators.
 	// See the Operator type for more info.
+	//
+	// Use RegisterOperator to add operators once queries may be built concurrently,
+	// as writing to the map directly isn't safe for concurrent use.
 	Operators = map[string]Operator{}
 
 	// DefaultChainingStrategy defines how clauses are glued together. Eg: using an `OR` statement
@@ -62,6 +116,16 @@ var (
 	//
 	// It defaults to 1 but is configurable either globally or on an individual basis when calling `ToSQL`.
 	DefaultPlaceholderStategyIndexOffset = 1
+
+	// DefaultOperator defines the operator used for fields whose tag doesn't specify one (eg: `filter:"name"`).
+	//
+	// It defaults to `eq` but is configurable either globally or on an individual basis when calling `ToSQL`.
+	DefaultOperator = "eq"
+
+	// DefaultObserver is called with the query and args each time a query is built, eg: to record which
+	// filters are used. It's unset by default and configurable either globally (set it before building
+	// any queries, as it isn't safe to change concurrently) or on an individual basis using WithObserver.
+	DefaultObserver func(query string, args []any)
 )
 
 // Opts defines the options that are used when running `ToSQL`.
@@ -76,6 +140,91 @@ type Opts struct {
 	ChainingStrategy    ChainingStrategy
 	PlaceholderStrategy PlaceholderStrategy
 	PlaceholderOffset   int
+	DefaultOperator     string
+
+	// TagName defines the struct tag the filter is read from, defaults to the global TagName.
+	TagName string
+
+	// Separator joins the clauses verbatim instead of the chaining strategy, when set.
+	Separator string
+
+	// PrettyPrint puts each clause on its own line, prefixed by the chaining strategy, when set.
+	PrettyPrint bool
+
+	// StrictFields causes an error to be returned for exported fields lacking a filter tag.
+	// Fields can be explicitly excluded using the `filter:"-"` tag.
+	StrictFields bool
+
+	// AllowedColumns restricts the columns clauses can reference, when set.
+	AllowedColumns map[string]bool
+
+	// ColumnTag names a secondary struct tag (eg: json) to read the column from
+	// when the filter tag doesn't specify one.
+	ColumnTag string
+
+	// ColumnMapper transforms the column of each clause, when set.
+	ColumnMapper func(fieldName, tagColumn string) string
+
+	// StrictColumns causes an error to be returned for columns that aren't plain identifiers.
+	StrictColumns bool
+
+	// MaxInElements limits the number of elements the in and not-in operators accept, when set.
+	MaxInElements int
+
+	// InChunkSize splits the in and not-in operators into comparisons of at most this many elements, when set.
+	InChunkSize int
+
+	// AutoInForSlices compares slices and arrays using in (or not-in) rather than eq (or neq), when set.
+	AutoInForSlices bool
+
+	// WrapClauses wraps each clause in parentheses, when set.
+	WrapClauses bool
+
+	// OuterParentheses wraps the whole query in parentheses, when set and the query isn't empty.
+	OuterParentheses bool
+
+	// EmptyInBehavior defines what the in and not-in operators result in for an empty slice.
+	EmptyInBehavior EmptyInBehavior
+
+	// SkipEmptySlices skips in and not-in clauses of empty slices, like nil values, when set.
+	SkipEmptySlices bool
+
+	// NilAsIsNull compares nil values of eq and neq clauses using IS NULL and IS NOT NULL, when set.
+	NilAsIsNull bool
+
+	// TimePrecision truncates bound time values to the given precision, when set.
+	TimePrecision time.Duration
+
+	// ValueTransformer transforms each bound value, by the column of its clause, when set.
+	ValueTransformer func(col string, v any) any
+
+	// ArrayBinder wraps the slices bound as a single array value (see AsArray), when set.
+	ArrayBinder func(v any) any
+
+	// ZeroTimeAsNull skips time.Time values that hold the zero time, like nil values, when set.
+	ZeroTimeAsNull bool
+
+	// TimeFormat formats bound time values as strings using the given layout, when set.
+	TimeFormat string
+
+	// Dialect selects the operator variants specific to a SQL dialect, when set.
+	Dialect Dialect
+
+	// DefaultPredicate is used as the query when the filter doesn't result in any clauses.
+	DefaultPredicate string
+
+	// Operators holds the operators registered for this call only, taking precedence
+	// over the globally registered operators.
+	Operators map[string]Operator
+
+	// Overrides replaces the values of the fields filtering on the columns, see WithOverride.
+	Overrides map[string]any
+
+	// Observer is called with the query and args once the query is built successfully, when set.
+	Observer func(query string, args []any)
+
+	// context passed to the operators, when building the query using ToSQLContext
+	ctx context.Context
 }
 
 func DefaultOpts() *Opts {
@@ -83,6 +232,9 @@ func DefaultOpts() *Opts {
 		ChainingStrategy:    DefaultChainingStrategy,
 		PlaceholderStrategy: DefaultPlaceholderStrategy,
 		PlaceholderOffset:   DefaultPlaceholderStategyIndexOffset,
+		DefaultOperator:     DefaultOperator,
+		TagName:             TagName,
+		Observer:            DefaultObserver,
 	}
 }
 
@@ -94,42 +246,490 @@ func WithChainingStrategy(typ ChainingStrategy) OptFn {
 	}
 }
 
+// WithTagName reads the filter from the given struct tag (eg: `qf:"name"`) for this call only,
+// rather than from the tag configured globally using TagName.
+func WithTagName(name string) OptFn {
+	return func(o *Opts) {
+		o.TagName = name
+	}
+}
+
+// WithSeparator joins the clauses using the given separator verbatim (eg: " AND\n\t"), rather than
+// the chaining strategy surrounded by spaces. This is meant for advanced cases like formatting the query,
+// so make sure the separator is a valid conjunction.
+func WithSeparator(sep string) OptFn {
+	return func(o *Opts) {
+		o.Separator = sep
+	}
+}
+
+// WithPrettyPrint puts each clause on its own indented line, prefixed by the chaining strategy, eg:
+//
+//	status = ?
+//		AND age > ?
+//
+// The args are the same as without pretty printing. This is meant for readable queries while developing
+// (eg: in logs), rather than for production queries. A separator set using WithSeparator takes precedence.
+func WithPrettyPrint(enabled bool) OptFn {
+	return func(o *Opts) {
+		o.PrettyPrint = enabled
+	}
+}
+
 func WithPlaceholderStrategy(strategy PlaceholderStrategy) OptFn {
 	return func(o *Opts) {
 		o.PlaceholderStrategy = strategy
 	}
 }
 
+// WithPlaceholderOffset sets the number of the first numbered placeholder (eg: `$3` rather than `$1`), which is
+// useful when the query already binds values before the filter. The offset should be 1 or more for the
+// numbered placeholder strategies, ToSQL returns an error wrapping ErrInvalidPlaceholderOffset otherwise.
+// It's ignored by PlaceholderStrategyQuestionmark.
 func WithPlaceholderOffset(offset int) OptFn {
 	return func(o *Opts) {
 		o.PlaceholderOffset = offset
 	}
 }
 
+// WithDefaultOperator sets the operator used for fields whose tag doesn't specify one.
+func WithDefaultOperator(name string) OptFn {
+	return func(o *Opts) {
+		o.DefaultOperator = name
+	}
+}
+
+// WithStrictFields makes ToSQL return an error when it encounters an exported field
+// without a filter tag. This helps catch fields that were forgotten to be annotated.
+// Fields that are intentionally left out can be tagged with `filter:"-"`.
+func WithStrictFields(strict bool) OptFn {
+	return func(o *Opts) {
+		o.StrictFields = strict
+	}
+}
+
+// WithAllowedColumns restricts the columns that can be referenced by the filter struct
+// to the given set, causing ToSQL to return an error for any other column.
+//
+// As opposed to the values, column names are interpolated directly into the query,
+// so this offers a defense-in-depth measure against tampered or mistyped tags.
+// It's off by default, allowing any column.
+func WithAllowedColumns(cols ...string) OptFn {
+	return func(o *Opts) {
+		o.AllowedColumns = make(map[string]bool, len(cols))
+		for _, col := range cols {
+			o.AllowedColumns[col] = true
+		}
+	}
+}
+
+// WithColumnFromTag makes the column fall back to the name in the given struct tag
+// when the filter tag omits it. This avoids repeating column names that are already
+// present in, for example, `json` tags:
+//
+//	type Filter struct {
+//		Status string `json:"status" filter:",op=eq"`
+//	}
+func WithColumnFromTag(tagName string) OptFn {
+	return func(o *Opts) {
+		o.ColumnTag = tagName
+	}
+}
+
+// WithColumnMapper sets a function that translates the column of each field before it's used in the query.
+// The function receives the name of the struct field and the column defined by the tag (which is empty when
+// the tag omits it), and returns the column to use. This allows deriving columns from a central mapping,
+// or applying a prefix to all columns, eg:
+//
+//	WithColumnMapper(func(fieldName, tagColumn string) string {
+//		return "tasks." + tagColumn
+//	})
+func WithColumnMapper(fn func(fieldName, tagColumn string) string) OptFn {
+	return func(o *Opts) {
+		o.ColumnMapper = fn
+	}
+}
+
+// WithStrictColumns makes ToSQL return an error for columns that contain anything other
+// than letters, digits, underscores and dots (for qualified names like `users.age`).
+//
+// Column names are interpolated directly into the query, so this guards against SQL injection
+// when tags are constructed dynamically. Tags flagged with `raw-col` (eg: `LOWER(email),op=eq,raw-col`)
+// are exempt, their column is emitted as is.
+func WithStrictColumns(strict bool) OptFn {
+	return func(o *Opts) {
+		o.StrictColumns = strict
+	}
+}
+
+// WithMaxInElements limits the number of elements the `in` and `not-in` operators accept, returning an error
+// wrapping ErrTooManyElements for larger slices. This protects against unusable queries, due to the limit
+// databases put on the number of bound values, when the slices originate from untrusted input.
+// It defaults to 0, which means unlimited.
+func WithMaxInElements(n int) OptFn {
+	return func(o *Opts) {
+		o.MaxInElements = n
+	}
+}
+
+// WithInChunkSize splits the `in` operator into multiple comparisons of at most n elements each, joined using OR,
+// eg: `(id IN(?,?) OR id IN(?))`. Likewise, `not-in` is split into comparisons joined using AND.
+// This keeps each list under the limits some databases and drivers put on its size.
+// It defaults to 0, which means the elements are never split.
+func WithInChunkSize(n int) OptFn {
+	return func(o *Opts) {
+		o.InChunkSize = n
+	}
+}
+
+// WithAutoInForSlices promotes the eq operator to in, and neq to not-in, for fields holding slices or arrays.
+// This is convenient for fields without an explicit operator, eg: `filter:"status"` holding []string{"todo", "doing"}
+// results in `status IN(?,?)`. Without this option, such fields result in an error suggesting the operator to use.
+func WithAutoInForSlices(enabled bool) OptFn {
+	return func(o *Opts) {
+		o.AutoInForSlices = enabled
+	}
+}
+
+// WithNilAsIsNull compares nil values using IS NULL rather than skipping the clause, eg: a nil *string under eq
+// results in `name IS NULL`, and under neq (or not-eq) in `name IS NOT NULL`. This only applies to the eq,
+// neq and not-eq operators, nil values of other operators (eg: gt) are skipped regardless, as comparing
+// against NULL isn't meaningful for them. Use the `nil-is-null` tag flag to enable this for a single field.
+func WithNilAsIsNull(enabled bool) OptFn {
+	return func(o *Opts) {
+		o.NilAsIsNull = enabled
+	}
+}
+
+// WithWrapClauses wraps each clause in parentheses before joining them, eg: `(age > ?) OR (name = ?)`.
+//
+// This guards against operator precedence surprises when embedding the query into larger boolean expressions,
+// or when operators result in segments combining several conditions.
+func WithWrapClauses(wrap bool) OptFn {
+	return func(o *Opts) {
+		o.WrapClauses = wrap
+	}
+}
+
+// WithOuterParentheses wraps the whole query in a single pair of parentheses, eg: `(name = ? AND age > ?)`.
+// This is useful when combining the query with a hand-written predicate using OR.
+// An empty query (eg: when none of the fields are set) is left empty.
+func WithOuterParentheses(wrap bool) OptFn {
+	return func(o *Opts) {
+		o.OuterParentheses = wrap
+	}
+}
+
+// WithEmptyInBehavior configures the query segment the `in` and `not-in` operators result in
+// for an empty slice. It defaults to EmptyInNull, see EmptyInLogical to have an empty `not-in` match all rows.
+func WithEmptyInBehavior(behavior EmptyInBehavior) OptFn {
+	return func(o *Opts) {
+		o.EmptyInBehavior = behavior
+	}
+}
+
+// WithSkipEmptySlices omits the `in` and `not-in` clauses of empty (non-nil) slices altogether, as is done
+// for nil values, rather than rendering them according to the EmptyInBehavior.
+// Use this when an empty selection means the filter doesn't apply, rather than that nothing matches.
+func WithSkipEmptySlices(skip bool) OptFn {
+	return func(o *Opts) {
+		o.SkipEmptySlices = skip
+	}
+}
+
+// WithTimePrecision truncates the bound time.Time values to the given precision (eg: time.Second).
+//
+// This avoids mismatches when comparing against columns with a lower precision than Go's nanoseconds,
+// like MySQL's DATETIME which stores seconds only.
+func WithTimePrecision(precision time.Duration) OptFn {
+	return func(o *Opts) {
+		o.TimePrecision = precision
+	}
+}
+
+// WithValueTransformer transforms each value before it's bound, receiving the column of the clause it belongs to.
+// This allows normalizing values (eg: lowercasing an enum) without handling every field by hand, eg:
+//
+//	WithValueTransformer(func(col string, v any) any {
+//		if s, ok := v.(string); ok && col == "status" {
+//			return strings.ToLower(strings.TrimSpace(s))
+//		}
+//		return v
+//	})
+//
+// The transformer runs for every value, including each of the elements of a slice expanded by `in`.
+// It runs after the time options (eg: WithTimeFormat) are applied.
+func WithValueTransformer(fn func(col string, v any) any) OptFn {
+	return func(o *Opts) {
+		o.ValueTransformer = fn
+	}
+}
+
+// WithArrayBinder sets the function wrapping the slices bound as a single array value, as done by the array
+// operators (eg: any and array-overlap) and operators returning values using AsArray. Drivers like pgx
+// bind slices out of the box, for github.com/lib/pq pass pq.Array, eg:
+//
+//	query, args, err := queryfilter.ToSQL(f, queryfilter.WithArrayBinder(func(v any) any {
+//		return pq.Array(v)
+//	}))
+//
+// The binder runs after the ValueTransformer, which receives the slice as is.
+func WithArrayBinder(fn func(v any) any) OptFn {
+	return func(o *Opts) {
+		o.ArrayBinder = fn
+	}
+}
+
+// WithZeroTimeAsNull skips fields holding the zero time.Time (0001-01-01), like nil pointers are skipped.
+// This allows using time.Time values rather than pointers for optional time filters.
+func WithZeroTimeAsNull(enabled bool) OptFn {
+	return func(o *Opts) {
+		o.ZeroTimeAsNull = enabled
+	}
+}
+
+// WithTimeFormat binds time.Time values as strings formatted using the given layout
+// (eg: "2006-01-02 15:04:05"), rather than passing the time.Time to the driver as is.
+//
+// This helps with drivers or columns expecting times in a specific textual representation.
+// When combined with WithTimePrecision, the time is truncated before being formatted.
+func WithTimeFormat(layout string) OptFn {
+	return func(o *Opts) {
+		o.TimeFormat = layout
+	}
+}
+
+// WithOperator registers an operator for a single call, rather than globally using RegisterOperator.
+// This avoids mutating global state, which leaks across the whole program and isn't safe when
+// operators are registered concurrently. Operators registered using this option take precedence
+// over the globally registered (and dialect specific) operators with the same name.
+func WithOperator(name string, op Operator) OptFn {
+	return func(o *Opts) {
+		if o.Operators == nil {
+			o.Operators = map[string]Operator{}
+		}
+
+		o.Operators[name] = op
+	}
+}
+
+// WithOverride replaces the value of the fields filtering on the column for a single call, without mutating
+// the filter, eg: injecting the tenant ID into a shared filter. The value is read like the value of the field,
+// so it's coerced the same way, and a nil value skips the clause. Overrides apply to the fields of the filter
+// itself, not those within a group. ToSQL returns an error wrapping ErrUnknownOverride when none of the fields
+// filter on the column, so a misspelled column doesn't silently leave the filter as is.
+//
+// ToSQLMerged applies the overrides to each of the filters, returning the error only when none of the filters
+// have a field filtering on the column. ToSQLFromMap applies them to the keys by column.
+func WithOverride(column string, value any) OptFn {
+	return func(o *Opts) {
+		if o.Overrides == nil {
+			o.Overrides = map[string]any{}
+		}
+
+		o.Overrides[column] = value
+	}
+}
+
+// WithOperatorOverrides registers the operators for a single call, like WithOperator does for a single
+// operator, eg: swapping like for ilike when targeting PostgreSQL. The operators are merged into the
+// operators registered for the call, so the options are applied in order: an operator passed using a later
+// WithOperator or WithOperatorOverrides replaces an operator with the same name passed earlier.
+func WithOperatorOverrides(operators map[string]Operator) OptFn {
+	return func(o *Opts) {
+		for name, op := range operators {
+			WithOperator(name, op)(o)
+		}
+	}
+}
+
+// WithObserver sets the function called with the query and args once the query is built, replacing the
+// DefaultObserver for this call, eg: to record metrics on the filters in use. It's called right before the query
+// is returned, and isn't called when building the query fails. All functions building a query call it once:
+// ToSQL, ToSQLContext, ToSQLMerged (and Combine), ToSQLFromMap, ToSQLNamed and ToSQLNamedArgs, as well as the
+// functions built on top of these (eg: WhereClause and AsSqlizer). ToSQLNamed passes the values in the order of
+// the placeholders and ToSQLNamedArgs passes them as sql.NamedArg. The clauses of a group are reported as part
+// of the query holding them. Pass nil to disable the DefaultObserver for a single call.
+func WithObserver(fn func(query string, args []any)) OptFn {
+	return func(o *Opts) {
+		o.Observer = fn
+	}
+}
+
+// WithDefaultPredicate sets the predicate (eg: a tautology like `1=1`) that is returned as the query
+// when the filter doesn't result in any clauses, so the query can always be interpolated into a
+// `WHERE` statement without checking whether it's empty.
+func WithDefaultPredicate(predicate string) OptFn {
+	return func(o *Opts) {
+		o.DefaultPredicate = predicate
+	}
+}
+
 // ToSQL takes a filter struct and returns a parameterized SQL string
 // and its values in order to be applied in a query.
+//
+// The clauses, and their values, are rendered strictly in the order the fields are declared in,
+// so with positional placeholders (eg: $1, $2) it's clear which value maps to which placeholder.
+// Fields that don't result in a clause (eg: untagged fields or nil values) leave no gaps.
+//
+// Tagged fields of embedded structs are promoted and rendered alongside the fields
+// of the outer struct, in the order they are declared. When an embedded struct is a pointer,
+// its fields are skipped for as long as the pointer is nil.
+//
+// The args hold normalized values, regardless of the exact type of the fields: signed integers are bound as int64,
+// unsigned integers as uint64, floats as float64, (named) strings as string, booleans as bool, times as
+// time.Time and maps as a JSON document (string). A json.Number is bound as an int64 (or float64 when it isn't
+// an integer), big.Int and big.Float as their decimal string and structs implementing fmt.Stringer as their string.
+// Drivers rely on these types, so they're kept stable.
+//
+// When none of the fields result in a clause (eg: all fields are nil pointers), an empty query
+// and no args are returned. See WhereClause and WithDefaultPredicate for ways to deal with this.
 func ToSQL(f any, fns ...OptFn) (query string, args []any, err error) {
+	return ToSQLContext(context.Background(), f, fns...)
+}
+
+// ToSQLContext works like ToSQL, but passes the context along to the operators through the clauses,
+// so operators can read request scoped values from it (eg: the current tenant). See Clause.Context.
+func ToSQLContext(ctx context.Context, f any, fns ...OptFn) (query string, args []any, err error) {
 	opts := DefaultOpts()
+	opts.ctx = ctx
 	for _, fn := range fns {
 		fn(opts)
 	}
 
-	clauses, err := buildClauses(f)
+	clauses, err := buildClauses(f, opts)
 	if err != nil {
 		return "", nil, err
 	}
 
 	sql, args, err := toSQL(clauses, opts)
-	sql = applyPlaceholders(sql, opts)
+	if err != nil {
+		return "", nil, err
+	}
 
-	return sql, args, err
+	query = completeQuery(sql, opts)
+	observe(query, args, opts)
+
+	return query, args, nil
+}
+
+// observe calls the observer with the query and args once the query is built successfully, see WithObserver.
+func observe(query string, args []any, opts *Opts) {
+	if opts.Observer != nil {
+		opts.Observer(query, args)
+	}
+}
+
+// BuildClauses takes a filter struct and returns the clauses it results in, without generating
+// the SQL string. Clauses that are skipped when generating the SQL (eg: nil values) are excluded,
+// so the returned clauses reflect what will actually be rendered.
+//
+// This is useful for logging or asserting on the parsed column, operator and value of each clause.
+func BuildClauses(f any, fns ...OptFn) ([]Clause, error) {
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	clauses, err := buildClauses(f, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make([]Clause, 0, len(clauses))
+	for _, c := range clauses {
+		// skip nil values
+		if c.Val == nil {
+			continue
+		}
+
+		rendered = append(rendered, c)
+	}
+
+	return rendered, nil
+}
+
+// RangeClauses calls fn for each clause the filter results in, in declaration order, without collecting
+// them in a slice first. Like BuildClauses, clauses that are skipped when generating the SQL (eg: nil values)
+// are excluded. It stops at the first error, either from reading the filter or returned by fn as is.
+//
+// This is useful for tooling processing large filters, eg: streaming the clauses into a writer.
+func RangeClauses(f any, fn func(Clause) error, fns ...OptFn) error {
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	overridden := map[string]bool{}
+	err := rangeClauses(f, opts, overridden, func(c Clause) error {
+		// skip nil values
+		if c.Val == nil {
+			return nil
+		}
+
+		return fn(c)
+	})
+	if err != nil {
+		return err
+	}
+
+	return checkOverrides(opts, overridden)
+}
+
+// WhereClause works like ToSQL, but prefixes the resulting query with `WHERE`. When the filter
+// doesn't result in any clauses, an empty string is returned instead, so the result can be
+// appended to a query unconditionally:
+//
+//	where, args, err := WhereClause(filter)
+//	query := fmt.Sprintf("SELECT * FROM tasks %s", where)
+func WhereClause(f any, fns ...OptFn) (string, []any, error) {
+	query, args, err := ToSQL(f, fns...)
+	if err != nil || query == "" {
+		return "", args, err
+	}
+
+	return "WHERE " + query, args, nil
 }
 
 func toSQL(clauses []Clause, opts *Opts) (string, []any, error) {
-	var (
-		segs []string
-		args []any
-	)
+	segs, err := renderSegments(clauses, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sql, args := joinSegments(segs, opts)
+	return sql, args, nil
+}
+
+// completeQuery finishes the joined clauses into the query returned to the caller: wrapping it in
+// parentheses when configured, falling back to the default predicate when empty and applying the placeholders.
+func completeQuery(sql string, opts *Opts) string {
+	return applyPlaceholders(wrapQuery(sql, opts), opts)
+}
+
+// wrapQuery wraps the joined clauses in parentheses when configured, falling back to the default predicate when empty.
+func wrapQuery(sql string, opts *Opts) string {
+	if sql != "" && opts.OuterParentheses {
+		sql = fmt.Sprintf("(%s)", sql)
+	}
+
+	if sql == "" {
+		sql = opts.DefaultPredicate
+	}
+
+	return sql
+}
+
+// segment holds the rendered query segment of a single clause, along with the values it binds.
+type segment struct {
+	clause Clause
+	sql    string
+	args   []any
+}
+
+// renderSegments runs the operator of each clause, skipping clauses without a value.
+func renderSegments(clauses []Clause, opts *Opts) ([]segment, error) {
+	segs := make([]segment, 0, len(clauses))
 
 	for _, c := range clauses {
 		// skip nil values
@@ -137,100 +737,407 @@ func toSQL(clauses []Clause, opts *Opts) (string, []any, error) {
 			continue
 		}
 
-		operator, ok := Operators[c.Op]
-		if !ok {
-			return "", nil, fmt.Errorf("operator %s is not available", c.Op)
+		// groups don't have a column, their clauses are checked when rendering the group
+		if opts.AllowedColumns != nil && !opts.AllowedColumns[c.Col] && c.Col != "" {
+			return nil, c.wrapErr(ErrColumnNotAllowed)
+		}
+
+		operator, err := lookupOperator(c, opts)
+		if err != nil {
+			return nil, err
 		}
 
-		sql, newArgs, err := operator(c)
+		sql, newArgs, skip, err := renderOperator(operator, c)
 		if err != nil {
-			return "", nil, err
+			return nil, c.wrapErr(err)
+		}
+
+		// an empty full segment (eg: a group without clauses) is skipped
+		if skip {
+			continue
 		}
 
-		segs = append(segs, fmt.Sprintf("%s %s", c.Col, sql))
-		args = append(args, newArgs...)
+		segs = append(segs, segment{
+			clause: c,
+			sql:    sql,
+			args:   newArgs,
+		})
+	}
+
+	return segs, nil
+}
+
+// joinSegments glues the segments together using the configured chaining strategy,
+// and returns the values of all segments, prepared to be bound to the query.
+func joinSegments(segs []segment, opts *Opts) (string, []any) {
+	// count the args upfront, so they're allocated once
+	n := 0
+	for _, s := range segs {
+		n += len(s.args)
+	}
+
+	var (
+		sqls = make([]string, len(segs))
+		args = make([]any, 0, n)
+	)
+
+	for i, s := range segs {
+		sqls[i] = s.sql
+		if opts.WrapClauses {
+			sqls[i] = fmt.Sprintf("(%s)", s.sql)
+		}
+
+		for _, arg := range s.args {
+			args = append(args, bindArg(arg, s.clause.Col, opts))
+		}
 	}
 
 	sep := fmt.Sprintf(" %s ", opts.ChainingStrategy)
-	return strings.Join(segs, sep), args, nil
+	switch {
+	case opts.Separator != "":
+		sep = opts.Separator
+	case opts.PrettyPrint:
+		sep = fmt.Sprintf("\n\t%s ", opts.ChainingStrategy)
+	}
+
+	return strings.Join(sqls, sep), args
+}
+
+// segmentColumns returns the column of each of the values of the segments, which is the column of the clause
+// of the segment or, for the values of a group, the column of the clause within the group.
+func segmentColumns(segs []segment) []string {
+	var cols []string
+	for _, s := range segs {
+		for _, arg := range s.args {
+			col := s.clause.Col
+			if bound, ok := arg.(boundArg); ok {
+				col = bound.col
+			}
+
+			cols = append(cols, col)
+		}
+	}
+
+	return cols
+}
+
+// bindArg prepares a value returned by the operator of the clause on the given column to be bound to the query.
+// Values that are already bound (eg: the values of a group) are returned as is.
+func bindArg(arg any, col string, opts *Opts) any {
+	if bound, ok := arg.(boundArg); ok {
+		return bound.value
+	}
+
+	array, isArray := arg.(arrayArg)
+	if isArray {
+		arg = array.value
+	}
+
+	if t, ok := arg.(time.Time); ok {
+		if opts.TimePrecision > 0 {
+			t = t.Truncate(opts.TimePrecision)
+		}
+
+		arg = t
+		if opts.TimeFormat != "" {
+			arg = t.Format(opts.TimeFormat)
+		}
+	}
+
+	if opts.ValueTransformer != nil {
+		arg = opts.ValueTransformer(col, arg)
+	}
+
+	if isArray && opts.ArrayBinder != nil {
+		arg = opts.ArrayBinder(arg)
+	}
+
+	return arg
+}
+
+// lookupOperator returns the operator registered for the operation of the clause,
+// preferring the operators registered for the call and the variant registered for the configured dialect.
+func lookupOperator(c Clause, opts *Opts) (Operator, error) {
+	operator, ok := findOperator(c.Op, opts)
+	if !ok {
+		return nil, c.wrapErr(fmt.Errorf("operator %s: %w", c.Op, ErrUnknownOperator))
+	}
+
+	return operator, nil
+}
+
+func findOperator(name string, opts *Opts) (Operator, bool) {
+	if operator, ok := opts.Operators[name]; ok {
+		return operator, true
+	}
+
+	return registeredOperator(opts.Dialect, name)
+}
+
+// validateOpts checks whether the options are valid, before building any clauses.
+func validateOpts(opts *Opts) error {
+	if !opts.ChainingStrategy.valid() {
+		return fmt.Errorf("%w: %q", ErrInvalidChainingStrategy, opts.ChainingStrategy)
+	}
+
+	// numbered placeholders start at 1, as `$0` (or lower) isn't valid
+	if opts.PlaceholderStrategy != PlaceholderStrategyQuestionmark && opts.PlaceholderOffset < 1 {
+		return fmt.Errorf("%w: %d", ErrInvalidPlaceholderOffset, opts.PlaceholderOffset)
+	}
+
+	if _, ok := findOperator(opts.DefaultOperator, opts); !ok {
+		return fmt.Errorf("default operator %s: %w", opts.DefaultOperator, ErrUnknownOperator)
+	}
+
+	return nil
 }
 
 func applyPlaceholders(q string, opts *Opts) string {
 	switch opts.PlaceholderStrategy {
+	// the query already uses questionmarks, escaped questionmarks (??) are kept, see PlaceholderList
 	case PlaceholderStrategyQuestionmark:
-		return replace(q, opts.PlaceholderOffset, defaultReplacer)
+		return q
 
 	case PlaceholderStrategyColon:
 		return replace(q, opts.PlaceholderOffset, colonReplacer)
 
 	case PlaceholderStrategyDollar:
 		return replace(q, opts.PlaceholderOffset, dollarReplacer)
+
+	case PlaceholderStrategyAt:
+		return replace(q, opts.PlaceholderOffset, atReplacer)
 	}
 
 	return ""
 }
 
-func buildClauses(f any) ([]Clause, error) {
-	t := reflect.TypeOf(f)
-	if t.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("unable to build filter: provided value is not a struct")
+func buildClauses(f any, opts *Opts) ([]Clause, error) {
+	overridden := map[string]bool{}
+	clauses, err := collectClauses(f, opts, overridden)
+	if err != nil {
+		return nil, err
 	}
 
-	v := reflect.ValueOf(f)
-	fields := reflect.VisibleFields(t)
-	clauses := make([]Clause, len(fields))
+	if err := checkOverrides(opts, overridden); err != nil {
+		return nil, err
+	}
+
+	return clauses, nil
+}
+
+// collectClauses returns the clauses of the filter, marking the overridden columns without checking
+// for unknown overrides, so the overrides can be checked across multiple filters (see ToSQLMerged).
+func collectClauses(f any, opts *Opts, overridden map[string]bool) ([]Clause, error) {
+	var clauses []Clause
+	err := rangeClauses(f, opts, overridden, func(c Clause) error {
+		clauses = append(clauses, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return clauses, nil
+}
+
+// rangeClauses calls fn for the clause of each tagged field of the filter in declaration order,
+// stopping at the first error returned by either reading a field or fn. The columns of which the value
+// is overridden are marked in overridden, see checkOverrides.
+func rangeClauses(f any, opts *Opts, overridden map[string]bool, fn func(Clause) error) error {
+	if err := validateOpts(opts); err != nil {
+		return err
+	}
+
+	v, err := structValue(f)
+	if err != nil {
+		return err
+	}
+
+	// the indexes of the group fields, whose promoted fields are rendered by the group instead
+	var groups [][]int
+
+	for _, field := range reflect.VisibleFields(v.Type()) {
+		if withinGroup(field.Index, groups) {
+			continue
+		}
 
-	for idx, field := range fields {
-		tag, ok := field.Tag.Lookup(TagName)
+		tag, ok := field.Tag.Lookup(opts.TagName)
 		if !ok {
+			// embedded structs are not filters themselves, their promoted fields are checked instead
+			if opts.StrictFields && field.IsExported() && !field.Anonymous {
+				return fmt.Errorf("field %s is missing a %s tag", field.Name, opts.TagName)
+			}
 			continue
 		}
 
-		rawValue := v.FieldByName(field.Name)
-		if !rawValue.IsValid() {
+		// explicitly skipped field
+		if tag == skipTag {
 			continue
 		}
 
-		column, operator, err := parseTag(tag)
+		if !field.IsExported() {
+			return fmt.Errorf("field %s: %w", field.Name, ErrUnexportedField)
+		}
+
+		// fields promoted from a nil embedded pointer can't be read and are skipped
+		rawValue, err := v.FieldByIndexErr(field.Index)
+		if err != nil || !rawValue.IsValid() {
+			continue
+		}
+
+		parsed, err := parseTag(tag)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("field %s: %w", field.Name, err)
 		}
 
-		val, err := readValue(rawValue)
+		column, operator, err := resolveClause(field, parsed, tag, opts)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		if parsed.flags["group"] {
+			groups = append(groups, field.Index)
+		}
+
+		if rawValue, err = readTaggedValue(v, rawValue, parsed); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		if rawValue = overrideValue(rawValue, column, opts, overridden); !rawValue.IsValid() {
+			continue
+		}
+
+		clause := Clause{
+			Field:     field.Name,
+			Col:       column,
+			Op:        operator,
+			params:    parsed.params,
+			nilIsNull: opts.NilAsIsNull || parsed.flags["nil-is-null"],
+			opts:      opts,
 		}
 
-		clauses[idx] = Clause{
-			Col: column,
-			Op:  operator,
-			Val: val,
+		if err := clause.readValue(rawValue); err != nil {
+			return err
+		}
 
-			// store the dereferenced reflected value for later use
-			reflectedValue: derefIfApplicable(rawValue),
+		if err := fn(clause); err != nil {
+			return err
 		}
 	}
 
-	return clauses, nil
+	return nil
 }
 
-func derefIfApplicable(v reflect.Value) reflect.Value {
-	if v.Kind() == reflect.Ptr {
-		return v.Elem()
+// overrideValue returns the value overriding the value of the field when set, marking the column as overridden.
+// A nil override results in an invalid value, skipping the clause.
+func overrideValue(rawValue reflect.Value, column string, opts *Opts, overridden map[string]bool) reflect.Value {
+	override, ok := opts.Overrides[column]
+	if !ok {
+		return rawValue
 	}
-	return v
+
+	overridden[column] = true
+	return reflect.ValueOf(override)
 }
 
-func readValue(v reflect.Value) (any, error) {
-	// dereference pointer first if applicable
+// checkOverrides returns an error when an override doesn't match any of the overridden columns.
+func checkOverrides(opts *Opts, overridden map[string]bool) error {
+	for column := range opts.Overrides {
+		if !overridden[column] {
+			return fmt.Errorf("%w: %s", ErrUnknownOverride, column)
+		}
+	}
+
+	return nil
+}
+
+// structValue returns the reflected filter struct, which is passed either as is or as a pointer.
+func structValue(f any) (reflect.Value, error) {
+	v := reflect.ValueOf(f)
+
+	// allow passing a pointer to the filter struct
 	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, fmt.Errorf("unable to build filter: %w: got nil pointer", ErrNotStruct)
+		}
 		v = v.Elem()
 	}
 
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("unable to build filter: %w", ErrNotStruct)
+	}
+
+	return v, nil
+}
+
+// columnFromTag reads the column name from the given struct tag of the field,
+// following the `name,options` convention used by tags like json.
+func columnFromTag(field reflect.StructField, tagName string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tagName), ",")
+	if name == skipTag {
+		return ""
+	}
+
+	return strings.TrimSpace(name)
+}
+
+func derefIfApplicable(v reflect.Value) reflect.Value {
+	// unwrap nested pointers (eg: **int) and interfaces (eg: an any holding an int),
+	// a nil at any level results in an invalid value
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v
+}
+
+// isComposite reports whether the value is a map, a struct (other than time.Time) or a slice of structs.
+func isComposite(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Struct:
+		return v.Type() != reflect.TypeOf(time.Time{})
+	case reflect.Slice, reflect.Array:
+		elem := v.Type().Elem()
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		return elem.Kind() == reflect.Struct && elem != reflect.TypeOf(time.Time{})
+	default:
+		return false
+	}
+}
+
+// readComposite returns the map, struct or slice as is, for operators that interpret them themselves.
+// A nil map and an empty slice are considered unset, like a nil pointer.
+func readComposite(v reflect.Value) any {
+	if v.Kind() == reflect.Map && v.IsNil() {
+		return nil
+	}
+
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() == 0 {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+// readValue reads the value to bind from the reflected value, normalizing it to one of a few dynamic types:
+// signed integers to int64, unsigned integers to uint64, floats to float64, strings (including named string
+// types) to string, booleans to bool and time.Time as is. Slices and arrays result in a []any of their elements
+// and maps in a JSON document (string). See readNumber for json.Number, big.Int and big.Float.
+func readValue(v reflect.Value) (any, error) {
+	// dereference pointers and interfaces first if applicable
+	v = derefIfApplicable(v)
+
 	if !v.IsValid() {
 		return nil, nil
 	}
 
+	if number, ok, err := readNumber(v); ok {
+		return number, err
+	}
+
 	// then try to determine the type and return the correct type
 	switch v.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -249,40 +1156,337 @@ func readValue(v reflect.Value) (any, error) {
 		return v.Bool(), nil
 
 	case reflect.Array, reflect.Slice:
-		args := make([]string, v.Len())
-		for i := 0; i < v.Len(); i++ {
-			args[i] = v.Index(i).String()
+		return readSliceElems(v)
+
+	case reflect.Map:
+		// maps are bound as a JSON document (eg: for a json column), nil maps are skipped like nil pointers
+		if v.IsNil() {
+			return nil, nil
 		}
-		return args, nil
+
+		doc, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to marshal map: %v", ErrUnsupportedType, err)
+		}
+		return string(doc), nil
 
 	case reflect.Struct:
-		// not parsing (custom) structs at this time,
-		// with the only exception being the time.Time
+		// not parsing (custom) structs at this time, with the exception of time.Time
+		// and types implementing fmt.Stringer (eg: domain values), bound as their string
 		if t, ok := v.Interface().(time.Time); ok {
 			return t, nil
 		}
-		return nil, fmt.Errorf("structs are not supported, only time.Time")
+		if s, ok := readStringer(v); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("%w: structs are not supported, only time.Time and fmt.Stringer", ErrUnsupportedType)
 
 	default:
-		return nil, fmt.Errorf("unsupported type: %v", v.Kind())
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, v.Kind())
+	}
+}
+
+// readStringer reads the string of a struct implementing fmt.Stringer, using either a value or a pointer receiver.
+func readStringer(v reflect.Value) (string, bool) {
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), true
+	}
+
+	// methods with a pointer receiver require an addressable value, so call them on a copy
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	if s, ok := ptr.Interface().(fmt.Stringer); ok {
+		return s.String(), true
+	}
+
+	return "", false
+}
+
+// readNumber reads the numeric types that aren't identified by their kind: json.Number (as decoded by a
+// json.Decoder using UseNumber) is read as an int64, or a float64 when it isn't an integer. Arbitrary precision
+// big.Int and big.Float values are read as their decimal string, as they may not fit in an int64 or float64.
+func readNumber(v reflect.Value) (any, bool, error) {
+	switch v.Type() {
+	case jsonNumberType:
+		// an empty number is considered unset, like a nil pointer
+		number := json.Number(v.String())
+		if number == "" {
+			return nil, true, nil
+		}
+
+		if i, err := number.Int64(); err == nil {
+			return i, true, nil
+		}
+
+		f, err := number.Float64()
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: invalid json.Number %q", ErrUnsupportedType, number)
+		}
+		return f, true, nil
+
+	case bigIntType, bigFloatType:
+		// copy the value, as the String methods have a pointer receiver
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+
+		if f, ok := ptr.Interface().(*big.Float); ok {
+			return f.Text('g', -1), true, nil
+		}
+		return ptr.Interface().(fmt.Stringer).String(), true, nil
 	}
+
+	return nil, false, nil
 }
 
-func parseTag(tag string) (column, operator string, err error) {
-	col, operator, found := strings.Cut(tag, ",")
+var (
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	bigIntType     = reflect.TypeOf(big.Int{})
+	bigFloatType   = reflect.TypeOf(big.Float{})
+)
+
+// resolveClause determines the column and operator of the field. Groups don't have a column of their own,
+// and use the group (or not-group when negated) operator.
+func resolveClause(field reflect.StructField, parsed parsedTag, tag string, opts *Opts) (string, string, error) {
+	if parsed.flags["group"] {
+		if parsed.flags["negate"] {
+			return "", "not-group", nil
+		}
+		return "", "group", nil
+	}
+
+	column, err := resolveColumn(field, parsed, tag, opts)
+	if err != nil {
+		return "", "", err
+	}
+
+	// if theres no operator defined, use the default operator
+	operator := parsed.operator
+	if operator == "" {
+		operator = opts.DefaultOperator
+	}
+
+	if _, ok := nullPromotions[operator]; parsed.flags["nil-is-null"] && !ok {
+		return "", "", fmt.Errorf("%w: nil-is-null requires eq, neq or not-eq; got %s", ErrInvalidTag, operator)
+	}
+
+	return column, operator, nil
+}
+
+// withinGroup reports whether the field with the given index is promoted from one of the group fields.
+func withinGroup(index []int, groups [][]int) bool {
+	for _, group := range groups {
+		if len(index) > len(group) && reflect.DeepEqual(index[:len(group)], group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveColumn determines the column of the field, read from its tag, the column tag or the column mapper.
+func resolveColumn(field reflect.StructField, parsed parsedTag, tag string, opts *Opts) (string, error) {
+	column := parsed.column
+	if column == "" && opts.ColumnTag != "" {
+		column = columnFromTag(field, opts.ColumnTag)
+	}
+
+	if opts.ColumnMapper != nil {
+		column = opts.ColumnMapper(field.Name, column)
+	}
 
-	// if theres no operator defined, default to equality
-	if !found {
-		return col, "eq", nil
+	if column == "" {
+		return "", fmt.Errorf("%w: missing column: %s", ErrInvalidTag, tag)
+	}
+
+	if opts.StrictColumns && !parsed.flags["raw-col"] && !identifierPattern.MatchString(column) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidColumn, column)
+	}
+
+	return column, nil
+}
+
+// errorType is the reflected type of the error interface.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// readSource calls the method with the given name on the filter struct, returning the value it results in.
+// The method takes no arguments and returns either a single value, or a value and an error.
+func readSource(filter reflect.Value, name string) (reflect.Value, error) {
+	returnsErr, err := checkSource(filter.Type(), name)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	// take the address of (a copy of) the filter, so methods with a pointer receiver are found too
+	ptr := reflect.New(filter.Type())
+	ptr.Elem().Set(filter)
+
+	out := ptr.MethodByName(name).Call(nil)
+	if returnsErr && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("source method %s: %w", name, out[1].Interface().(error))
+	}
+
+	return out[0], nil
+}
+
+// checkSource checks whether the filter struct type has a source method with the given name, including the
+// methods with a pointer receiver, and reports whether the method returns an error next to the value.
+func checkSource(t reflect.Type, name string) (bool, error) {
+	method, ok := reflect.PointerTo(t).MethodByName(name)
+	if !ok {
+		return false, fmt.Errorf("%w: source method %s not found", ErrInvalidTag, name)
+	}
+
+	// the type of the method includes the receiver as its first argument
+	mt := method.Type
+	returnsErr := mt.NumOut() == 2 && mt.Out(1) == errorType
+	if mt.NumIn() != 1 || (mt.NumOut() != 1 && !returnsErr) {
+		return false, fmt.Errorf(
+			"%w: source method %s should take no arguments and return a value and optionally an error",
+			ErrInvalidTag, name,
+		)
+	}
+
+	return returnsErr, nil
+}
+
+// readTaggedValue returns the value of the field, as adjusted by its tag: read from the method given as the
+// source instead of the field and replaced by the default when it's the zero value, when set.
+func readTaggedValue(filter, rawValue reflect.Value, parsed parsedTag) (reflect.Value, error) {
+	var err error
+	if source, ok := parsed.params["source"]; ok {
+		if rawValue, err = readSource(filter, source); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	if value, ok := parsed.params["default"]; ok && rawValue.IsZero() {
+		return readDefault(value, rawValue.Type())
+	}
+
+	return rawValue, nil
+}
+
+// readDefault coerces the default value from the tag (eg: `limit,op=lte,default=100`) to the given type,
+// dereferencing pointer types. Times are read using time.RFC3339.
+func readDefault(value string, t reflect.Type) (reflect.Value, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	v := reflect.New(t).Elem()
+	var err error
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		var parsed time.Time
+		parsed, err = time.Parse(time.RFC3339, value)
+		v.Set(reflect.ValueOf(parsed))
+
+	case t.Kind() == reflect.String:
+		v.SetString(value)
+
+	case t.Kind() == reflect.Bool:
+		var parsed bool
+		parsed, err = strconv.ParseBool(value)
+		v.SetBool(parsed)
+
+	case v.CanInt():
+		var parsed int64
+		parsed, err = strconv.ParseInt(value, 10, t.Bits())
+		v.SetInt(parsed)
+
+	case v.CanUint():
+		var parsed uint64
+		parsed, err = strconv.ParseUint(value, 10, t.Bits())
+		v.SetUint(parsed)
+
+	case v.CanFloat():
+		var parsed float64
+		parsed, err = strconv.ParseFloat(value, t.Bits())
+		v.SetFloat(parsed)
+
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: default isn't supported for %s", ErrInvalidTag, t)
+	}
+
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: default %q for %s: %v", ErrInvalidTag, value, t, err)
+	}
+
+	return v, nil
+}
+
+// parsedTag holds the parts of a filter tag, eg: `metadata,op=json-path,path=country`.
+type parsedTag struct {
+	column   string
+	operator string
+
+	// params holds the key=value segments following the column, other than the operator
+	params map[string]string
+
+	// flags holds the segments without a value, see tagFlags
+	flags map[string]bool
+}
+
+// parseTag parses the column, operator and parameters from the tag.
+// The column is either the first segment (eg: `status,op=in`) or set explicitly using a `col=` or `column=`
+// segment, in which case the segments can be in any order (eg: `op=in,col=status`).
+// The operator is empty when the tag doesn't define one, as is the column when it's left to the column tag.
+func parseTag(tag string) (parsedTag, error) {
+	var parsed parsedTag
+
+	for i, segment := range strings.Split(tag, ",") {
+		// split the segment eg: `op=eq` into its key and value
+		key, value, found := strings.Cut(segment, "=")
+		if !found {
+			if i == 0 {
+				// only the first segment can hold the column without a key
+				parsed.column = segment
+				continue
+			}
+
+			flag := strings.TrimSpace(segment)
+			if !tagFlags[flag] {
+				return parsedTag{}, fmt.Errorf("%w: %s", ErrInvalidTag, tag)
+			}
+
+			if parsed.flags == nil {
+				parsed.flags = map[string]bool{}
+			}
+			parsed.flags[flag] = true
+			continue
+		}
+
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		// both `=eq` and `op=` are malformed, rather than falling back to a default
+		if key == "" || (value == "" && (key == "op" || key == "col" || key == "column")) {
+			return parsedTag{}, fmt.Errorf("%w: %s", ErrInvalidTag, tag)
+		}
+
+		switch key {
+		case "op":
+			parsed.operator = value
+
+		case "col", "column":
+			if parsed.column != "" {
+				return parsedTag{}, fmt.Errorf("%w: ambiguous column: %s", ErrInvalidTag, tag)
+			}
+			parsed.column = value
+
+		default:
+			if parsed.params == nil {
+				parsed.params = map[string]string{}
+			}
+			parsed.params[key] = value
+		}
 	}
 
-	// split the string eg: `op=eq`to get the name of the operator here
-	_, op, found := strings.Cut(operator, "=")
-	if !found {
-		return "", "", fmt.Errorf("incorrectly formatted tag: %s", tag)
+	if parsed.flags["negate"] && !parsed.flags["group"] {
+		return parsedTag{}, fmt.Errorf("%w: negate requires group: %s", ErrInvalidTag, tag)
 	}
 
-	return col, strings.TrimSpace(op), nil
+	return parsed, nil
 }
 
 // readSliceElems takes a reflect.Value of a slice/array
@@ -1,7 +1,13 @@
 package queryfilter
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,7 +32,7 @@ func TestToSQLSimpleTypes(t *testing.T) {
 
 	assert.Nil(t, e)
 	assert.EqualValues(t, eq, q)
-	assert.ElementsMatch(t, ev, v)
+	assert.Equal(t, ev, v)
 }
 
 func TestToSQLSimpleTypesNoPointers(t *testing.T) {
@@ -47,7 +53,7 @@ func TestToSQLSimpleTypesNoPointers(t *testing.T) {
 
 	assert.Nil(t, e)
 	assert.EqualValues(t, eq, q)
-	assert.ElementsMatch(t, ev, v)
+	assert.Equal(t, ev, v)
 }
 
 func TestToSQLWithSlice(t *testing.T) {
@@ -73,7 +79,7 @@ func TestToSQLWithSlice(t *testing.T) {
 
 	assert.Nil(t, e)
 	assert.EqualValues(t, eq, q)
-	assert.ElementsMatch(t, ev, v)
+	assert.Equal(t, ev, v)
 }
 
 func TestToSQLWithEmptySlice(t *testing.T) {
@@ -94,6 +100,54 @@ func TestToSQLWithEmptySlice(t *testing.T) {
 	assert.ElementsMatch(t, ev, v)
 }
 
+func TestToSQLWithEmptyInBehavior(t *testing.T) {
+	type filter struct {
+		Colors []string `filter:"color,op=in"`
+		Brands []string `filter:"brand,op=not-in"`
+		Sizes  []string `filter:"size,op=not-in"`
+	}
+
+	f := filter{Sizes: []string{"S", "M"}}
+
+	q, v, e := ToSQL(f, WithEmptyInBehavior(EmptyInNull))
+	assert.Nil(t, e)
+	assert.Equal(t, "color IN(NULL) AND brand NOT IN(NULL) AND size NOT IN(?,?)", q)
+	assert.Equal(t, []any{"S", "M"}, v)
+
+	q, v, e = ToSQL(f, WithEmptyInBehavior(EmptyInLogical), WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "1=0 AND 1=1 AND size NOT IN($1,$2)", q)
+	assert.Equal(t, []any{"S", "M"}, v)
+}
+
+func TestToSQLWithSkipEmptySlices(t *testing.T) {
+	type filter struct {
+		Colors *[]string `filter:"color,op=in"`
+		Brands []string  `filter:"brand,op=not-in"`
+		Sizes  []string  `filter:"size,op=in"`
+	}
+
+	// nil slices are always skipped
+	q, v, e := ToSQL(filter{Sizes: []string{"S"}})
+	assert.Nil(t, e)
+	assert.Equal(t, "brand NOT IN(NULL) AND size IN(?)", q)
+	assert.Equal(t, []any{"S"}, v)
+
+	// empty slices are compared against NULL by default
+	empty := []string{}
+	f := filter{Colors: &empty, Brands: []string{}, Sizes: []string{"S"}}
+	q, v, e = ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "color IN(NULL) AND brand NOT IN(NULL) AND size IN(?)", q)
+	assert.Equal(t, []any{"S"}, v)
+
+	// or skipped like nil slices, when enabled
+	q, v, e = ToSQL(f, WithSkipEmptySlices(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "size IN(?)", q)
+	assert.Equal(t, []any{"S"}, v)
+}
+
 func TestToSQLBetween(t *testing.T) {
 	type filter struct {
 		PriceRange *[]float64 `filter:"price,op=between"`
@@ -207,6 +261,31 @@ func TestToSQLInWrongType(t *testing.T) {
 	_, _, e := ToSQL(f)
 	assert.NotNil(t, e)
 	assert.ErrorContainsf(t, e, "slice or array; got string", "wrong error")
+	assert.ErrorIs(t, e, ErrTypeMismatch)
+	assert.ErrorContains(t, e, "field Tags (column title): type mismatch: expected slice or array; got string for operation in")
+}
+
+func TestToSQLErrorContainsFieldName(t *testing.T) {
+	type unsupported struct {
+		Data chan string `filter:"data"`
+	}
+
+	_, _, e := ToSQL(unsupported{Data: make(chan string)})
+	assert.ErrorContains(t, e, "field Data (column data): unsupported type: chan")
+
+	type unknownOperator struct {
+		Name string `filter:"name,op=unknown"`
+	}
+
+	_, _, e = ToSQL(unknownOperator{Name: "bobby"})
+	assert.ErrorContains(t, e, "field Name (column name): operator unknown: operator is not available")
+
+	type malformedTag struct {
+		Name string `filter:"name,unknown"`
+	}
+
+	_, _, e = ToSQL(malformedTag{Name: "bobby"})
+	assert.ErrorContains(t, e, "field Name: incorrectly formatted tag")
 }
 
 func TestAssertTypeOneOf(t *testing.T) {
@@ -258,3 +337,1565 @@ func TestAssertTypeOneOf(t *testing.T) {
 		assert.Nil(t, err, "expected no error")
 	}
 }
+
+func TestToSQLStrictFields(t *testing.T) {
+	type filter struct {
+		Name    string `filter:"name"`
+		Age     int
+		Skipped int `filter:"-"`
+		private int
+	}
+
+	f := filter{Name: "bobby", Age: 42, Skipped: 1, private: 2}
+
+	_, _, e := ToSQL(f, WithStrictFields(true))
+	assert.ErrorContains(t, e, "field Age is missing a filter tag")
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+}
+
+func TestToSQLStrictFieldsSkipTag(t *testing.T) {
+	type filter struct {
+		Name    string `filter:"name"`
+		Skipped int    `filter:"-"`
+	}
+
+	q, v, e := ToSQL(filter{Name: "bobby", Skipped: 1}, WithStrictFields(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+}
+
+func TestToSQLSkipTag(t *testing.T) {
+	type filter struct {
+		Name    string `filter:"name"`
+		Skipped string `filter:"-"`
+	}
+
+	q, v, e := ToSQL(filter{Name: "bobby", Skipped: "ignored"})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+}
+
+func TestToSQLPointerToStruct(t *testing.T) {
+	type filter struct {
+		Name   string `filter:"name,op=eq"`
+		MinAge int    `filter:"age,op=gt"`
+	}
+
+	f := filter{Name: "bobby", MinAge: 42}
+
+	eq, ev, ee := ToSQL(f)
+	q, v, e := ToSQL(&f)
+
+	assert.Nil(t, ee)
+	assert.Nil(t, e)
+	assert.Equal(t, eq, q)
+	assert.Equal(t, ev, v)
+}
+
+func TestToSQLNilPointerToStruct(t *testing.T) {
+	type filter struct {
+		Name string `filter:"name,op=eq"`
+	}
+
+	var f *filter
+	_, _, e := ToSQL(f)
+	assert.ErrorIs(t, e, ErrNotStruct)
+	assert.ErrorContains(t, e, "nil pointer")
+
+	_, _, e = ToSQL(nil)
+	assert.ErrorIs(t, e, ErrNotStruct)
+}
+
+func TestToSQLAllowedColumns(t *testing.T) {
+	type filter struct {
+		Name   string `filter:"name"`
+		MinAge int    `filter:"age,op=gt"`
+	}
+
+	f := filter{Name: "bobby", MinAge: 42}
+
+	q, v, e := ToSQL(f, WithAllowedColumns("name", "age"))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ? AND age > ?", q)
+	assert.Equal(t, []any{"bobby", int64(42)}, v)
+
+	_, _, e = ToSQL(f, WithAllowedColumns("name"))
+	assert.ErrorIs(t, e, ErrColumnNotAllowed)
+	assert.ErrorContains(t, e, "field MinAge (column age): column is not allowed")
+}
+
+func TestToSQLColumnFromTag(t *testing.T) {
+	type filter struct {
+		Status string `json:"status,omitempty" filter:",op=eq"`
+		MinAge int    `json:"min_age" filter:"age,op=gt"`
+		Name   string `json:"name" filter:""`
+	}
+
+	f := filter{Status: "todo", MinAge: 42, Name: "bobby"}
+
+	q, v, e := ToSQL(f, WithColumnFromTag("json"))
+	assert.Nil(t, e)
+	assert.Equal(t, "status = ? AND age > ? AND name = ?", q)
+	assert.Equal(t, []any{"todo", int64(42), "bobby"}, v)
+
+	_, _, e = ToSQL(f)
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "field Status")
+}
+
+func TestToSQLStrictColumns(t *testing.T) {
+	type valid struct {
+		Name   string `filter:"users.name"`
+		MinAge int    `filter:"_age,op=gt"`
+	}
+
+	q, _, e := ToSQL(valid{Name: "bobby", MinAge: 42}, WithStrictColumns(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "users.name = ? AND _age > ?", q)
+
+	type spaces struct {
+		Name string `filter:"name OR 1"`
+	}
+
+	type semicolon struct {
+		Name string `filter:"name;DROP TABLE users"`
+	}
+
+	type parentheses struct {
+		Name string `filter:"LOWER(name)"`
+	}
+
+	for _, f := range []any{spaces{}, semicolon{}, parentheses{}} {
+		_, _, e := ToSQL(f, WithStrictColumns(true))
+		assert.ErrorIs(t, e, ErrInvalidColumn)
+
+		_, _, e = ToSQL(f)
+		assert.Nil(t, e)
+	}
+}
+
+func TestToSQLRawColumn(t *testing.T) {
+	type filter struct {
+		Email *string `filter:"LOWER(email),op=eq,raw-col"`
+		Name  *string `filter:"name"`
+	}
+
+	email, name := "jane@example.com", "jane"
+	q, v, e := ToSQL(filter{Email: &email, Name: &name}, WithStrictColumns(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "LOWER(email) = ? AND name = ?", q)
+	assert.Equal(t, []any{email, name}, v)
+
+	// the flag applies to the clause it's set on only
+	type unflagged struct {
+		Email *string `filter:"LOWER(email),op=eq"`
+	}
+
+	_, _, e = ToSQL(unflagged{Email: &email}, WithStrictColumns(true))
+	assert.ErrorIs(t, e, ErrInvalidColumn)
+
+	parsed, e := parseTag("LOWER(email), raw-col ,op=eq")
+	assert.Nil(t, e)
+	assert.Equal(t, parsedTag{column: "LOWER(email)", operator: "eq", flags: map[string]bool{"raw-col": true}}, parsed)
+}
+
+func TestToSQLWithTimePrecision(t *testing.T) {
+	type filter struct {
+		DueBy  time.Time   `filter:"due,op=gt"`
+		Window []time.Time `filter:"created,op=between"`
+	}
+
+	due := time.Date(2023, 5, 5, 12, 30, 15, 123456789, time.UTC)
+	f := filter{
+		DueBy:  due,
+		Window: []time.Time{due, due.Add(time.Hour)},
+	}
+
+	truncated := time.Date(2023, 5, 5, 12, 30, 15, 0, time.UTC)
+
+	_, v, e := ToSQL(f, WithTimePrecision(time.Second))
+	assert.Nil(t, e)
+	assert.Equal(t, []any{truncated, truncated, truncated.Add(time.Hour)}, v)
+
+	_, v, e = ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, []any{due, due, due.Add(time.Hour)}, v)
+}
+
+func TestBuildClauses(t *testing.T) {
+	type filter struct {
+		Name     *string  `filter:"name"`
+		MinAge   int      `filter:"age,op=gt"`
+		Colors   []string `filter:"color,op=in"`
+		Untagged string
+	}
+
+	clauses, e := BuildClauses(filter{MinAge: 42, Colors: []string{"red"}})
+	assert.Nil(t, e)
+	assert.Len(t, clauses, 2)
+
+	assert.Equal(t, "MinAge", clauses[0].Field)
+	assert.Equal(t, "age", clauses[0].Col)
+	assert.Equal(t, "gt", clauses[0].Op)
+	assert.Equal(t, int64(42), clauses[0].Val)
+
+	assert.Equal(t, "Colors", clauses[1].Field)
+	assert.Equal(t, "color", clauses[1].Col)
+	assert.Equal(t, "in", clauses[1].Op)
+
+	_, e = BuildClauses("not a struct")
+	assert.ErrorIs(t, e, ErrNotStruct)
+}
+
+func TestRangeClauses(t *testing.T) {
+	type filter struct {
+		Name   *string  `filter:"name"`
+		MinAge int      `filter:"age,op=gt"`
+		Colors []string `filter:"color,op=in"`
+	}
+
+	var cols []string
+	e := RangeClauses(filter{MinAge: 42, Colors: []string{"red"}}, func(c Clause) error {
+		cols = append(cols, c.Col)
+		return nil
+	})
+	assert.Nil(t, e)
+	assert.Equal(t, []string{"age", "color"}, cols)
+
+	// stops at the first error returned
+	errStop := errors.New("stop")
+	cols = nil
+	e = RangeClauses(filter{MinAge: 42, Colors: []string{"red"}}, func(c Clause) error {
+		cols = append(cols, c.Col)
+		return errStop
+	})
+	assert.ErrorIs(t, e, errStop)
+	assert.Equal(t, []string{"age"}, cols)
+
+	e = RangeClauses("not a struct", func(Clause) error { return nil })
+	assert.ErrorIs(t, e, ErrNotStruct)
+}
+
+func TestBuildClausesSkipsUntaggedFields(t *testing.T) {
+	type filter struct {
+		Untagged string
+		Name     *string `filter:"name"`
+		Skipped  string  `filter:"-"`
+		MinAge   int     `filter:"age,op=gt"`
+	}
+
+	clauses, e := buildClauses(filter{MinAge: 42}, DefaultOpts())
+	assert.Nil(t, e)
+	assert.Len(t, clauses, 2)
+	assert.Equal(t, "name", clauses[0].Col)
+	assert.Equal(t, "age", clauses[1].Col)
+}
+
+type Pagination struct {
+	AfterID *int `filter:"id,op=gt"`
+}
+
+type CommonFilters struct {
+	Pagination
+	Status *string `filter:"status"`
+}
+
+func TestToSQLEmbeddedStructs(t *testing.T) {
+	type filter struct {
+		CommonFilters
+		Name *string `filter:"name"`
+	}
+
+	afterID, status, name := 10, "todo", "bobby"
+	f := filter{
+		CommonFilters: CommonFilters{
+			Pagination: Pagination{AfterID: &afterID},
+			Status:     &status,
+		},
+		Name: &name,
+	}
+
+	q, v, e := ToSQL(f, WithStrictFields(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "id > ? AND status = ? AND name = ?", q)
+	assert.Equal(t, []any{int64(10), "todo", "bobby"}, v)
+}
+
+func TestToSQLEmbeddedStructPointer(t *testing.T) {
+	type filter struct {
+		*Pagination
+		Name *string `filter:"name"`
+	}
+
+	name, afterID := "bobby", 10
+
+	q, v, e := ToSQL(filter{Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+
+	q, v, e = ToSQL(filter{Pagination: &Pagination{AfterID: &afterID}, Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "id > ? AND name = ?", q)
+	assert.Equal(t, []any{int64(10), "bobby"}, v)
+}
+
+type Exclusions struct {
+	Status *string `filter:"status"`
+	MinAge *int    `filter:"age,op=gt"`
+}
+
+func TestToSQLGroups(t *testing.T) {
+	type filter struct {
+		Exclusions `filter:",group,negate"`
+		Name       *string    `filter:"name"`
+		Either     Exclusions `filter:",group,chain=or"`
+	}
+
+	status, age, name := "done", 65, "bobby"
+	f := filter{
+		Exclusions: Exclusions{Status: &status, MinAge: &age},
+		Name:       &name,
+		Either:     Exclusions{Status: &status, MinAge: &age},
+	}
+
+	q, v, e := ToSQL(f, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "NOT (status = $1 AND age > $2) AND name = $3 AND (status = $4 OR age > $5)", q)
+	assert.Equal(t, []any{"done", int64(65), "bobby", "done", int64(65)}, v)
+
+	d, e := DescribeText(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "not (status is done and age is greater than 65) and name is bobby and "+
+		"(status is done or age is greater than 65)", d)
+
+	assert.Nil(t, Validate(filter{}))
+
+	// empty groups are skipped, rather than rendering NOT ()
+	q, v, e = ToSQL(filter{Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+
+	// the columns of the group are subject to the allowed columns
+	_, _, e = ToSQL(f, WithAllowedColumns("name", "status"))
+	assert.ErrorIs(t, e, ErrColumnNotAllowed)
+	assert.ErrorContains(t, e, "field Exclusions: field MinAge (column age)")
+
+	type invalidChain struct {
+		Exclusions `filter:",group,chain=xor"`
+	}
+
+	_, _, e = ToSQL(invalidChain{Exclusions: Exclusions{Status: &status}})
+	assert.ErrorIs(t, e, ErrInvalidChainingStrategy)
+
+	type negateWithoutGroup struct {
+		Name *string `filter:"name,negate"`
+	}
+
+	_, _, e = ToSQL(negateWithoutGroup{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "negate requires group")
+}
+
+func TestWhereClause(t *testing.T) {
+	type filter struct {
+		Name *string `filter:"name"`
+	}
+
+	name := "bobby"
+	q, v, e := WhereClause(filter{Name: &name})
+	assert.Nil(t, e)
+	assert.Equal(t, "WHERE name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+
+	q, v, e = WhereClause(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+
+	_, _, e = WhereClause("not a struct")
+	assert.ErrorIs(t, e, ErrNotStruct)
+}
+
+func TestToSQLAllNilFields(t *testing.T) {
+	type filter struct {
+		Name   *string   `filter:"name"`
+		MinAge *int      `filter:"age,op=gt"`
+		Colors *[]string `filter:"color,op=in"`
+	}
+
+	q, v, e := ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+
+	q, v, e = ToSQL(filter{}, WithDefaultPredicate("1=1"))
+	assert.Nil(t, e)
+	assert.Equal(t, "1=1", q)
+	assert.Empty(t, v)
+
+	name := "bobby"
+	q, v, e = ToSQL(filter{Name: &name}, WithDefaultPredicate("1=1"))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{"bobby"}, v)
+}
+
+func TestToSQLPointerToSlices(t *testing.T) {
+	type filter struct {
+		Ints    *[]int     `filter:"int,op=in"`
+		Floats  *[]float64 `filter:"float,op=not-in"`
+		Strings *[]string  `filter:"string,op=in"`
+		Range   *[]int     `filter:"range,op=between"`
+	}
+
+	ints, floats, strings, rng := []int{1, 2}, []float64{1.5}, []string{"a", "b"}, []int{10, 20}
+	f := filter{Ints: &ints, Floats: &floats, Strings: &strings, Range: &rng}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "int IN(?,?) AND float NOT IN(?) AND string IN(?,?) AND range BETWEEN ? AND ?", q)
+	assert.Equal(t, []any{int64(1), int64(2), 1.5, "a", "b", int64(10), int64(20)}, v)
+
+	clauses, e := BuildClauses(f)
+	assert.Nil(t, e)
+	assert.Equal(t, []any{int64(1), int64(2)}, clauses[0].Val)
+	assert.Equal(t, []any{1.5}, clauses[1].Val)
+	assert.Equal(t, []any{"a", "b"}, clauses[2].Val)
+
+	// nil pointers are skipped
+	q, v, e = ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+
+	// empty but non-nil slices match nothing
+	emptyInts, emptyFloats, emptyStrings := []int{}, []float64{}, []string{}
+	q, v, e = ToSQL(filter{Ints: &emptyInts, Floats: &emptyFloats, Strings: &emptyStrings})
+	assert.Nil(t, e)
+	assert.Equal(t, "int IN(NULL) AND float NOT IN(NULL) AND string IN(NULL)", q)
+	assert.Empty(t, v)
+
+	_, _, e = ToSQL(filter{Range: &emptyInts})
+	assert.ErrorIs(t, e, ErrTypeMismatch)
+	assert.ErrorContains(t, e, "between expects two elements")
+}
+
+func BenchmarkToSQLWideIn(b *testing.B) {
+	type filter struct {
+		IDs    []int   `filter:"id,op=in"`
+		Name   *string `filter:"name"`
+		MinAge int     `filter:"age,op=gt"`
+	}
+
+	ids := make([]int, 500)
+	for i := range ids {
+		ids[i] = i
+	}
+
+	name := "bobby"
+	f := filter{IDs: ids, Name: &name, MinAge: 42}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := ToSQL(f); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestToSQLWithOperator(t *testing.T) {
+	type filter struct {
+		Title string `filter:"title,op=like"`
+		Name  string `filter:"name,op=eq"`
+	}
+
+	f := filter{Title: "%draft%", Name: "bobby"}
+
+	q, v, e := ToSQL(
+		f,
+		WithOperator("like", SimpleOperator("LIKE ?")),
+		WithOperator("eq", SimpleOperator("== ?")),
+	)
+	assert.Nil(t, e)
+	assert.Equal(t, "title LIKE ? AND name == ?", q)
+	assert.Equal(t, []any{"%draft%", "bobby"}, v)
+
+	// the operators don't leak into other calls
+	_, _, e = ToSQL(f)
+	assert.ErrorIs(t, e, ErrUnknownOperator)
+}
+
+func TestToSQLWithOperatorOverrides(t *testing.T) {
+	type filter struct {
+		Title string `filter:"title,op=like"`
+		Name  string `filter:"name,op=eq"`
+	}
+
+	f := filter{Title: "%draft%", Name: "bobby"}
+	overrides := map[string]Operator{
+		"like": SimpleOperator("ILIKE ?"),
+		"eq":   SimpleOperator("== ?"),
+	}
+
+	q, v, e := ToSQL(f, WithOperatorOverrides(overrides))
+	assert.Nil(t, e)
+	assert.Equal(t, "title ILIKE ? AND name == ?", q)
+	assert.Equal(t, []any{"%draft%", "bobby"}, v)
+
+	// later options replace the operators passed earlier
+	q, _, e = ToSQL(f, WithOperatorOverrides(overrides), WithOperator("like", SimpleOperator("LIKE ?")))
+	assert.Nil(t, e)
+	assert.Equal(t, "title LIKE ? AND name == ?", q)
+
+	q, _, e = ToSQL(f, WithOperator("like", SimpleOperator("LIKE ?")), WithOperatorOverrides(overrides))
+	assert.Nil(t, e)
+	assert.Equal(t, "title ILIKE ? AND name == ?", q)
+
+	// the overrides are copied, rather than modified by later options
+	_, _, e = ToSQL(f, WithOperatorOverrides(overrides), WithOperator("other", SimpleOperator("= ?")))
+	assert.Nil(t, e)
+	assert.Len(t, overrides, 2)
+}
+
+func TestToSQLWithObserver(t *testing.T) {
+	type group struct {
+		Name *string `filter:"name"`
+	}
+
+	type filter struct {
+		Status *string `filter:"status"`
+		Group  group   `filter:",group"`
+		MinAge *int    `filter:"age,op=gt"`
+	}
+
+	var observed []string
+	observer := func(query string, args []any) {
+		observed = append(observed, fmt.Sprintf("%s %v", query, args))
+	}
+
+	status, name := "todo", "bobby"
+	f := filter{Status: &status, Group: group{Name: &name}}
+
+	_, _, e := ToSQL(f, WithObserver(observer), WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(t, []string{"status = $1 AND (name = $2) [todo bobby]"}, observed)
+
+	// not called when building the query fails
+	_, _, e = ToSQL(f, WithObserver(observer), WithAllowedColumns("status"))
+	assert.ErrorIs(t, e, ErrColumnNotAllowed)
+	assert.Len(t, observed, 1)
+
+	DefaultObserver = observer
+	defer func() { DefaultObserver = nil }()
+
+	_, _, e = WhereClause(filter{Status: &status})
+	assert.Nil(t, e)
+	assert.Equal(t, "status = ? [todo]", observed[1])
+
+	_, _, e = ToSQL(f, WithObserver(nil))
+	assert.Nil(t, e)
+	assert.Len(t, observed, 2)
+}
+
+func TestObserverEntryPoints(t *testing.T) {
+	type filter struct {
+		Status *string `filter:"status"`
+	}
+
+	var observed []string
+	observe := WithObserver(func(query string, args []any) {
+		observed = append(observed, fmt.Sprintf("%s %v", query, args))
+	})
+
+	status := "todo"
+	f := filter{Status: &status}
+
+	_, _, e := ToSQLMerged(ChainingStrategyOr, []any{f, f}, observe)
+	assert.Nil(t, e)
+
+	_, _, e = ToSQLFromMap(map[string]any{"status": "todo"}, observe)
+	assert.Nil(t, e)
+
+	_, _, e = ToSQLNamed(f, observe)
+	assert.Nil(t, e)
+
+	_, _, e = ToSQLNamedArgs(f, observe)
+	assert.Nil(t, e)
+
+	assert.Equal(t, []string{
+		"(status = ?) OR (status = ?) [todo todo]",
+		"status = ? [todo]",
+		"status = :status [todo]",
+		"status = @status [{{} status todo}]",
+	}, observed)
+
+	DefaultObserver = func(query string, args []any) { observed = append(observed, query) }
+	defer func() { DefaultObserver = nil }()
+
+	_, _, e = Combine(ChainingStrategyAnd, f)
+	assert.Nil(t, e)
+	assert.Equal(t, "status = ?", observed[4])
+}
+
+func TestToSQLWithDefaultOperator(t *testing.T) {
+	type filter struct {
+		Title string `filter:"title"`
+		Name  string `filter:"name,op=eq"`
+	}
+
+	f := filter{Title: "%draft%", Name: "bobby"}
+
+	q, v, e := ToSQL(f, WithDefaultOperator("like"), WithOperator("like", SimpleOperator("LIKE ?")))
+	assert.Nil(t, e)
+	assert.Equal(t, "title LIKE ? AND name = ?", q)
+	assert.Equal(t, []any{"%draft%", "bobby"}, v)
+
+	_, _, e = ToSQL(f, WithDefaultOperator("unknown"))
+	assert.ErrorIs(t, e, ErrUnknownOperator)
+	assert.ErrorContains(t, e, "default operator unknown")
+}
+
+func TestToSQLTimeSlices(t *testing.T) {
+	type filter struct {
+		DueWindow *[]time.Time `filter:"due,op=between"`
+		Created   []time.Time  `filter:"created,op=in"`
+	}
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	window := []time.Time{start, end}
+
+	f := filter{
+		DueWindow: &window,
+		Created:   []time.Time{start, start.AddDate(0, 0, 1), end},
+	}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "due BETWEEN ? AND ? AND created IN(?,?,?)", q)
+	assert.Equal(t, []any{start, end, start, start.AddDate(0, 0, 1), end}, v)
+
+	clauses, e := BuildClauses(f)
+	assert.Nil(t, e)
+	assert.Equal(t, []any{start, end}, clauses[0].Val)
+}
+
+func TestToSQLTimeSlicesIn(t *testing.T) {
+	type filter struct {
+		Dates    *[]time.Time  `filter:"created,op=in"`
+		Excluded []*time.Time  `filter:"updated,op=not-in"`
+		Holidays *[2]time.Time `filter:"due,op=in"`
+	}
+
+	first := time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 0, 1)
+	dates := []time.Time{first, second}
+	holidays := [2]time.Time{first, second}
+
+	f := filter{Dates: &dates, Excluded: []*time.Time{&second}, Holidays: &holidays}
+	q, v, e := ToSQL(f, WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "created IN($1,$2) AND updated NOT IN($3) AND due IN($4,$5)", q)
+	assert.Equal(t, []any{first, second, second, first, second}, v)
+
+	// the time elements are bound following the time options, like single time values
+	q, v, e = ToSQL(filter{Dates: &dates, Excluded: []*time.Time{&second}}, WithTimeFormat("2006-01-02"))
+	assert.Nil(t, e)
+	assert.Equal(t, "created IN(?,?) AND updated NOT IN(?)", q)
+	assert.Equal(t, []any{"2023-05-05", "2023-05-06", "2023-05-06"}, v)
+}
+
+func TestToSQLWithColumnMapper(t *testing.T) {
+	type filter struct {
+		Status    string `filter:"status"`
+		StoryType string `filter:""`
+	}
+
+	columns := map[string]string{"StoryType": "story_type"}
+	mapper := func(fieldName, tagColumn string) string {
+		if tagColumn == "" {
+			tagColumn = columns[fieldName]
+		}
+
+		return "tasks." + tagColumn
+	}
+
+	q, v, e := ToSQL(filter{Status: "todo", StoryType: "bug"}, WithColumnMapper(mapper), WithStrictColumns(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "tasks.status = ? AND tasks.story_type = ?", q)
+	assert.Equal(t, []any{"todo", "bug"}, v)
+}
+
+func TestToSQLDeclarationOrder(t *testing.T) {
+	type filter struct {
+		Untagged string
+		Colors   []string `filter:"color,op=in"`
+		Name     *string  `filter:"name"`
+		Skipped  string   `filter:"-"`
+		MinAge   int      `filter:"age,op=gt"`
+		Title    *bool    `filter:"title,op=is-null"`
+		MaxAge   int      `filter:"age,op=lt"`
+	}
+
+	empty := true
+	f := filter{
+		Untagged: "ignored",
+		Colors:   []string{"red", "blue"},
+		Skipped:  "ignored",
+		MinAge:   18,
+		Title:    &empty,
+		MaxAge:   65,
+	}
+
+	q, v, e := ToSQL(f, WithPlaceholderStrategy(PlaceholderStrategyDollar))
+	assert.Nil(t, e)
+	assert.Equal(t, "color IN($1,$2) AND age > $3 AND title IS NULL AND age < $4", q)
+	assert.Equal(t, []any{"red", "blue", int64(18), int64(65)}, v)
+}
+
+func TestToSQLUnsignedIntegers(t *testing.T) {
+	type filter struct {
+		IDs    *[]uint64 `filter:"id,op=in"`
+		Ranks  []uint    `filter:"rank,op=in"`
+		Serial *uint64   `filter:"serial"`
+	}
+
+	ids := []uint64{1, math.MaxUint64}
+	serial := uint64(math.MaxUint64)
+
+	q, v, e := ToSQL(filter{IDs: &ids, Ranks: []uint{3, 4}, Serial: &serial})
+	assert.Nil(t, e)
+	assert.Equal(t, "id IN(?,?) AND rank IN(?,?) AND serial = ?", q)
+	assert.Equal(t, []any{uint64(1), uint64(math.MaxUint64), uint64(3), uint64(4), uint64(math.MaxUint64)}, v)
+}
+
+func TestToSQLWithTimeFormat(t *testing.T) {
+	type filter struct {
+		CreatedAfter *time.Time  `filter:"created_at,op=gt"`
+		UpdatedIn    []time.Time `filter:"updated_at,op=between"`
+	}
+
+	created := time.Date(2023, 4, 5, 6, 7, 8, 900, time.UTC)
+	updated := []time.Time{created, created.Add(time.Hour)}
+	f := filter{CreatedAfter: &created, UpdatedIn: updated}
+
+	_, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, []any{created, updated[0], updated[1]}, v)
+
+	_, v, e = ToSQL(f, WithTimeFormat("2006-01-02 15:04:05"))
+	assert.Nil(t, e)
+	assert.Equal(t, []any{"2023-04-05 06:07:08", "2023-04-05 06:07:08", "2023-04-05 07:07:08"}, v)
+
+	_, v, e = ToSQL(f, WithTimeFormat(time.RFC3339Nano), WithTimePrecision(time.Second))
+	assert.Nil(t, e)
+	assert.Equal(t, "2023-04-05T06:07:08Z", v[0])
+}
+
+func TestToSQLWithWrapClauses(t *testing.T) {
+	type filter struct {
+		Points []int    `filter:"story_points,op=range"`
+		Name   *string  `filter:"name"`
+		Tags   []string `filter:"tag,op=in"`
+	}
+
+	name := "jane"
+	f := filter{Points: []int{3, 8}, Name: &name, Tags: []string{"a", "b"}}
+
+	q, v, e := ToSQL(f, WithWrapClauses(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "(story_points >= ? AND story_points <= ?) AND (name = ?) AND (tag IN(?,?))", q)
+	assert.Equal(t, []any{int64(3), int64(8), name, "a", "b"}, v)
+
+	q, _, e = ToSQL(f, WithWrapClauses(true), WithChainingStrategy(ChainingStrategyOr))
+	assert.Nil(t, e)
+	assert.Equal(t, "(story_points >= ? AND story_points <= ?) OR (name = ?) OR (tag IN(?,?))", q)
+
+	q, _, e = ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "story_points >= ? AND story_points <= ? AND name = ? AND tag IN(?,?)", q)
+}
+
+func TestToSQLWithOuterParentheses(t *testing.T) {
+	type filter struct {
+		Name   *string `filter:"name"`
+		MinAge *int    `filter:"age,op=gt"`
+	}
+
+	name, age := "jane", 18
+
+	q, v, e := ToSQL(filter{Name: &name, MinAge: &age}, WithOuterParentheses(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "(name = ? AND age > ?)", q)
+	assert.Equal(t, []any{name, int64(age)}, v)
+
+	q, _, e = ToSQL(filter{Name: &name, MinAge: &age}, WithOuterParentheses(true), WithWrapClauses(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "((name = ?) AND (age > ?))", q)
+
+	// empty queries stay empty
+	q, v, e = ToSQL(filter{}, WithOuterParentheses(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+}
+
+func TestToSQLNestedPointersAndInterfaces(t *testing.T) {
+	type filter struct {
+		Name   **string `filter:"name"`
+		Age    any      `filter:"age,op=gt"`
+		Colors any      `filter:"color,op=in"`
+		Status *any     `filter:"status"`
+	}
+
+	name := "jane"
+	namePtr := &name
+	var status any = "done"
+
+	q, v, e := ToSQL(filter{Name: &namePtr, Age: 18, Colors: []string{"red"}, Status: &status})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ? AND age > ? AND color IN(?) AND status = ?", q)
+	assert.Equal(t, []any{name, int64(18), "red", "done"}, v)
+
+	// nils at any level are skipped
+	var nilName *string
+	var nilStatus any
+	q, v, e = ToSQL(filter{Name: &nilName, Status: &nilStatus})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+}
+
+func TestToSQLUnexportedTaggedField(t *testing.T) {
+	type filter struct {
+		Name      *string   `filter:"name"`
+		createdAt time.Time `filter:"created_at,op=gt"`
+		secret    string
+	}
+
+	name := "jane"
+	f := filter{Name: &name, createdAt: time.Now(), secret: "s3cr3t"}
+
+	_, _, e := ToSQL(f)
+	assert.ErrorIs(t, e, ErrUnexportedField)
+	assert.ErrorContains(t, e, "field createdAt")
+
+	// unexported fields without a tag, or explicitly skipped, are ignored
+	type skipped struct {
+		Name      *string   `filter:"name"`
+		createdAt time.Time `filter:"-"`
+		secret    string
+	}
+
+	q, _, e := ToSQL(skipped{Name: &name, createdAt: time.Now(), secret: "s3cr3t"})
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+}
+
+func TestToSQLInvalidChainingStrategy(t *testing.T) {
+	type filter struct {
+		Name   *string `filter:"name"`
+		MinAge *int    `filter:"age,op=gt"`
+	}
+
+	name, age := "jane", 18
+	f := filter{Name: &name, MinAge: &age}
+
+	q, _, e := ToSQL(f, WithChainingStrategy("GARBAGE"))
+	assert.ErrorIs(t, e, ErrInvalidChainingStrategy)
+	assert.ErrorContains(t, e, `"GARBAGE"`)
+	assert.Equal(t, "", q)
+
+	_, _, e = ToSQL(f, WithChainingStrategy("and"))
+	assert.ErrorIs(t, e, ErrInvalidChainingStrategy)
+}
+
+func TestToSQLWithPlaceholderOffset(t *testing.T) {
+	type filter struct {
+		Name   *string `filter:"name"`
+		MinAge *int    `filter:"age,op=gt"`
+	}
+
+	name, age := "jane", 18
+	f := filter{Name: &name, MinAge: &age}
+
+	q, _, e := ToSQL(f, WithPlaceholderStrategy(PlaceholderStrategyDollar), WithPlaceholderOffset(3))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = $3 AND age > $4", q)
+
+	for _, strategy := range []PlaceholderStrategy{
+		PlaceholderStrategyDollar, PlaceholderStrategyColon, PlaceholderStrategyAt,
+	} {
+		for _, offset := range []int{0, -1} {
+			q, _, e = ToSQL(f, WithPlaceholderStrategy(strategy), WithPlaceholderOffset(offset))
+			assert.ErrorIs(t, e, ErrInvalidPlaceholderOffset)
+			assert.Equal(t, "", q)
+		}
+	}
+
+	// the offset doesn't apply to questionmarks
+	q, _, e = ToSQL(f, WithPlaceholderOffset(0))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ? AND age > ?", q)
+}
+
+func TestToSQLWithSeparator(t *testing.T) {
+	type filter struct {
+		Name   *string `filter:"name"`
+		MinAge *int    `filter:"age,op=gt"`
+	}
+
+	name, age := "jane", 18
+
+	q, v, e := ToSQL(filter{Name: &name, MinAge: &age}, WithSeparator(" AND\n\t"))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ? AND\n\tage > ?", q)
+	assert.Equal(t, []any{name, int64(age)}, v)
+}
+
+func TestToSQLWithPrettyPrint(t *testing.T) {
+	type filter struct {
+		Name     *string  `filter:"name"`
+		MinAge   *int     `filter:"age,op=gt"`
+		Statuses []string `filter:"status,op=in"`
+	}
+
+	name, age := "jane", 18
+	f := filter{Name: &name, MinAge: &age, Statuses: []string{"todo", "doing"}}
+
+	q, v, e := ToSQL(f, WithPrettyPrint(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?\n\tAND age > ?\n\tAND status IN(?,?)", q)
+
+	_, expected, _ := ToSQL(f)
+	assert.Equal(t, expected, v)
+
+	q, _, e = ToSQL(f, WithPrettyPrint(true), WithChainingStrategy(ChainingStrategyOr))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?\n\tOR age > ?\n\tOR status IN(?,?)", q)
+}
+
+func TestToSQLWithDefault(t *testing.T) {
+	type filter struct {
+		Limit   int        `filter:"limit,op=lte,default=100"`
+		Status  *string    `filter:"status,default=todo"`
+		Ratio   float32    `filter:"ratio,op=gt,default=0.5"`
+		Active  bool       `filter:"active,default=true"`
+		DueDate *time.Time `filter:"due,op=before,default=2023-05-05T00:00:00Z"`
+	}
+
+	due := time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC)
+
+	q, v, e := ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "limit <= ? AND status = ? AND ratio > ? AND active = ? AND due < ?", q)
+	assert.Equal(t, []any{int64(100), "todo", float64(0.5), true, due}, v)
+
+	status := "done"
+	_, v, e = ToSQL(filter{Limit: 10, Status: &status, Ratio: 0.25, Active: true, DueDate: &due})
+	assert.Nil(t, e)
+	assert.Equal(t, []any{int64(10), "done", float64(0.25), true, due}, v)
+
+	type invalidDefault struct {
+		Limit int8 `filter:"limit,op=lte,default=1000"`
+	}
+
+	_, _, e = ToSQL(invalidDefault{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, `field Limit: incorrectly formatted tag: default "1000" for int8`)
+
+	type unsupportedDefault struct {
+		IDs []int `filter:"id,op=in,default=1"`
+	}
+
+	_, _, e = ToSQL(unsupportedDefault{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "default isn't supported for []int")
+}
+
+func TestToSQLWithOverride(t *testing.T) {
+	type filter struct {
+		Tenant   *int     `filter:"tenant_id"`
+		Statuses []string `filter:"status,op=in"`
+		Name     *string  `filter:"name"`
+	}
+
+	name := "bobby"
+	f := filter{Statuses: []string{"todo"}, Name: &name}
+
+	q, v, e := ToSQL(f, WithOverride("tenant_id", int32(42)), WithOverride("status", [2]string{"todo", "done"}))
+	assert.Nil(t, e)
+	assert.Equal(t, "tenant_id = ? AND status IN(?,?) AND name = ?", q)
+	assert.Equal(t, []any{int64(42), "todo", "done", "bobby"}, v)
+	assert.Nil(t, f.Tenant)
+	assert.Equal(t, []string{"todo"}, f.Statuses)
+
+	q, v, e = ToSQL(f, WithOverride("name", nil))
+	assert.Nil(t, e)
+	assert.Equal(t, "status IN(?)", q)
+	assert.Equal(t, []any{"todo"}, v)
+
+	_, _, e = ToSQL(f, WithOverride("tenant", 42))
+	assert.ErrorIs(t, e, ErrUnknownOverride)
+	assert.ErrorContains(t, e, "override doesn't match a column: tenant")
+
+	_, _, e = ToSQL(f, WithOverride("tenant_id", struct{}{}))
+	assert.ErrorIs(t, e, ErrUnsupportedType)
+}
+
+func TestToSQLWithNilAsIsNull(t *testing.T) {
+	type filter struct {
+		Name     *string `filter:"name"`
+		Archived *string `filter:"archived_at,op=neq"`
+		MinAge   *int    `filter:"age,op=gt"`
+		Tags     *[]int  `filter:"tags,op=in"`
+	}
+
+	q, v, e := ToSQL(filter{}, WithNilAsIsNull(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "name IS NULL AND archived_at IS NOT NULL", q)
+	assert.Empty(t, v)
+
+	name := "bobby"
+	q, v, e = ToSQL(filter{Name: &name}, WithNilAsIsNull(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ? AND archived_at IS NOT NULL", q)
+	assert.Equal(t, []any{"bobby"}, v)
+
+	// nil values are skipped by default
+	q, _, e = ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+
+	d, e := DescribeText(filter{}, WithNilAsIsNull(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "name is empty and archived_at is not empty", d)
+}
+
+func TestToSQLNilIsNullFlag(t *testing.T) {
+	type filter struct {
+		Parent *int    `filter:"parent_id,nil-is-null"`
+		Name   *string `filter:"name"`
+	}
+
+	q, v, e := ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "parent_id IS NULL", q)
+	assert.Empty(t, v)
+
+	parent := 1
+	q, v, e = ToSQL(filter{Parent: &parent})
+	assert.Nil(t, e)
+	assert.Equal(t, "parent_id = ?", q)
+	assert.Equal(t, []any{int64(1)}, v)
+
+	type invalidOperator struct {
+		MinAge *int `filter:"age,op=gt,nil-is-null"`
+	}
+
+	_, _, e = ToSQL(invalidOperator{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "field MinAge: incorrectly formatted tag: nil-is-null requires eq, neq or not-eq; got gt")
+
+	e = Validate(invalidOperator{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+}
+
+func TestToSQLSliceWithEqualityOperator(t *testing.T) {
+	type filter struct {
+		Statuses []string `filter:"status"`
+		Excluded [2]int   `filter:"id,op=neq"`
+		Name     *string  `filter:"name"`
+	}
+
+	name := "jane"
+	f := filter{Statuses: []string{"todo", "doing"}, Excluded: [2]int{1, 2}, Name: &name}
+
+	_, _, e := ToSQL(f)
+	assert.ErrorIs(t, e, ErrTypeMismatch)
+	assert.ErrorContains(t, e, "field Statuses (column status): type mismatch: operation eq doesn't support slice values, use op=in instead")
+
+	_, _, e = ToSQL(filter{Statuses: []string{"todo"}}, WithAutoInForSlices(false))
+	assert.ErrorContains(t, e, "operation eq doesn't support slice values")
+
+	q, v, e := ToSQL(f, WithAutoInForSlices(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "status IN(?,?) AND id NOT IN(?,?) AND name = ?", q)
+	assert.Equal(t, []any{"todo", "doing", int64(1), int64(2), name}, v)
+}
+
+func TestToSQLWithMaxInElements(t *testing.T) {
+	type filter struct {
+		IDs      []int    `filter:"id,op=in"`
+		Excluded []string `filter:"status,op=not-in"`
+	}
+
+	f := filter{IDs: []int{1, 2, 3}, Excluded: []string{"done"}}
+
+	q, v, e := ToSQL(f, WithMaxInElements(3))
+	assert.Nil(t, e)
+	assert.Equal(t, "id IN(?,?,?) AND status NOT IN(?)", q)
+	assert.Equal(t, []any{int64(1), int64(2), int64(3), "done"}, v)
+
+	_, _, e = ToSQL(f, WithMaxInElements(2))
+	assert.ErrorIs(t, e, ErrTooManyElements)
+	assert.ErrorContains(t, e, "field IDs (column id): too many elements: operation in got 3 elements; the maximum is 2")
+
+	_, _, e = ToSQL(filter{Excluded: []string{"done", "archived"}}, WithMaxInElements(1))
+	assert.ErrorIs(t, e, ErrTooManyElements)
+
+	// unlimited by default
+	_, _, e = ToSQL(filter{IDs: make([]int, 10000)})
+	assert.Nil(t, e)
+}
+
+func TestToSQLWithInChunkSize(t *testing.T) {
+	type filter struct {
+		IDs      []int    `filter:"id,op=in"`
+		Excluded []string `filter:"status,op=not-in"`
+		Name     *string  `filter:"name"`
+	}
+
+	name := "jane"
+	f := filter{IDs: []int{1, 2, 3, 4}, Excluded: []string{"done", "archived", "deleted"}, Name: &name}
+
+	q, v, e := ToSQL(f, WithInChunkSize(2), WithDialect(DialectPostgres))
+	assert.Nil(t, e)
+	assert.Equal(t, "(id IN($1,$2) OR id IN($3,$4)) AND "+
+		"(status NOT IN($5,$6) AND status NOT IN($7)) AND name = $8", q)
+	assert.Equal(t, []any{int64(1), int64(2), int64(3), int64(4), "done", "archived", "deleted", name}, v)
+
+	// lists within the chunk size are left as is
+	q, _, e = ToSQL(f, WithInChunkSize(4))
+	assert.Nil(t, e)
+	assert.Equal(t, "id IN(?,?,?,?) AND status NOT IN(?,?,?) AND name = ?", q)
+}
+
+type sourceFilter struct {
+	Email    string  `filter:"email,source=NormalizedEmail"`
+	Domain   string  `filter:"domain,op=in,source=Domains"`
+	Team     *string `filter:"team,source=TeamOrNil"`
+	Username string  `filter:"username,source=ValidUsername"`
+}
+
+func (f sourceFilter) NormalizedEmail() string {
+	return strings.ToLower(strings.TrimSpace(f.Email))
+}
+
+func (f *sourceFilter) Domains() []string {
+	return []string{f.Domain, "www." + f.Domain}
+}
+
+func (f sourceFilter) TeamOrNil() *string {
+	if f.Team == nil || *f.Team == "" {
+		return nil
+	}
+	return f.Team
+}
+
+func (f sourceFilter) ValidUsername() (string, error) {
+	if strings.Contains(f.Username, " ") {
+		return "", errors.New("username contains spaces")
+	}
+	return f.Username, nil
+}
+
+func (f sourceFilter) WithArgument(s string) string {
+	return s
+}
+
+func TestToSQLWithSourceMethod(t *testing.T) {
+	empty := ""
+	f := sourceFilter{Email: "  Jane@Example.com ", Domain: "example.com", Team: &empty, Username: "jane"}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "email = ? AND domain IN(?,?) AND username = ?", q)
+	assert.Equal(t, []any{"jane@example.com", "example.com", "www.example.com", "jane"}, v)
+
+	// pointers to the filter work the same
+	q, _, e = ToSQL(&f)
+	assert.Nil(t, e)
+	assert.Equal(t, "email = ? AND domain IN(?,?) AND username = ?", q)
+
+	f.Username = "jane doe"
+	_, _, e = ToSQL(f)
+	assert.ErrorContains(t, e, "field Username: source method ValidUsername: username contains spaces")
+
+	type unknownSource struct {
+		Email string `filter:"email,source=Unknown"`
+	}
+
+	_, _, e = ToSQL(unknownSource{Email: "jane@example.com"})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "source method Unknown not found")
+
+	type invalidSource struct {
+		sourceFilter
+		Other string `filter:"other,source=WithArgument"`
+	}
+
+	_, _, e = ToSQL(invalidSource{sourceFilter: sourceFilter{Email: "jane@example.com"}})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "source method WithArgument should take no arguments")
+}
+
+func TestToSQLWithZeroTimeAsNull(t *testing.T) {
+	type filter struct {
+		DueBefore    time.Time  `filter:"due_date,op=before"`
+		CreatedAfter *time.Time `filter:"created_at,op=after"`
+		Name         *string    `filter:"name"`
+	}
+
+	name := "jane"
+	var zero time.Time
+
+	q, v, e := ToSQL(filter{CreatedAfter: &zero, Name: &name}, WithZeroTimeAsNull(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ?", q)
+	assert.Equal(t, []any{name}, v)
+
+	due := time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC)
+	q, v, e = ToSQL(filter{DueBefore: due}, WithZeroTimeAsNull(true))
+	assert.Nil(t, e)
+	assert.Equal(t, "due_date < ?", q)
+	assert.Equal(t, []any{due}, v)
+
+	// zero times are bound by default
+	q, v, e = ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "due_date < ?", q)
+	assert.Equal(t, []any{zero}, v)
+}
+
+func TestToSQLArgTypes(t *testing.T) {
+	type status string
+
+	type filter struct {
+		Int     int       `filter:"int"`
+		Int8    int8      `filter:"int8"`
+		Int16   int16     `filter:"int16"`
+		Int32   int32     `filter:"int32"`
+		Int64   int64     `filter:"int64"`
+		Uint    uint      `filter:"uint"`
+		Uint8   uint8     `filter:"uint8"`
+		Uint16  uint16    `filter:"uint16"`
+		Uint32  uint32    `filter:"uint32"`
+		Uint64  uint64    `filter:"uint64"`
+		Float32 float32   `filter:"float32"`
+		Float64 float64   `filter:"float64"`
+		String  string    `filter:"string"`
+		Named   status    `filter:"named"`
+		Bool    bool      `filter:"bool"`
+		Time    time.Time `filter:"time"`
+		Slice   []int8    `filter:"slice,op=in"`
+	}
+
+	now := time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC)
+	f := filter{
+		Int: 1, Int8: 2, Int16: 3, Int32: 4, Int64: 5,
+		Uint: 6, Uint8: 7, Uint16: 8, Uint32: 9, Uint64: 10,
+		Float32: 1.5, Float64: 2.5,
+		String: "a", Named: "b", Bool: true, Time: now,
+		Slice: []int8{11},
+	}
+
+	_, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, []any{
+		int64(1), int64(2), int64(3), int64(4), int64(5),
+		uint64(6), uint64(7), uint64(8), uint64(9), uint64(10),
+		float64(1.5), float64(2.5),
+		"a", "b", true, now,
+		int64(11),
+	}, v)
+}
+
+func TestToSQLBoolEquality(t *testing.T) {
+	type filter struct {
+		IsActive *bool `filter:"active,op=eq"`
+	}
+
+	// false is bound as a value like true, only nil is skipped
+	for _, active := range []bool{true, false} {
+		active := active
+		q, v, e := ToSQL(filter{IsActive: &active}, WithDialect(DialectPostgres))
+		assert.Nil(t, e)
+		assert.Equal(t, "active = $1", q)
+		assert.Equal(t, []any{active}, v)
+	}
+
+	q, v, e := ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+}
+
+func TestToSQLNumbers(t *testing.T) {
+	type filter struct {
+		Count   json.Number   `filter:"count"`
+		Ratio   *json.Number  `filter:"ratio,op=gt"`
+		IDs     []json.Number `filter:"id,op=in"`
+		Balance *big.Int      `filter:"balance,op=gte"`
+		Amount  big.Float     `filter:"amount,op=lte"`
+	}
+
+	ratio := json.Number("0.5")
+	balance, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	f := filter{
+		Count:   json.Number("42"),
+		Ratio:   &ratio,
+		IDs:     []json.Number{"1", "2"},
+		Balance: balance,
+		Amount:  *big.NewFloat(1.25),
+	}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "count = ? AND ratio > ? AND id IN(?,?) AND balance >= ? AND amount <= ?", q)
+	assert.Equal(t, []any{int64(42), float64(0.5), int64(1), int64(2), "123456789012345678901234567890", "1.25"}, v)
+
+	// an empty json.Number is considered unset
+	q, v, e = ToSQL(filter{IDs: []json.Number{"3"}, Balance: balance})
+	assert.Nil(t, e)
+	assert.Equal(t, "id IN(?) AND balance >= ? AND amount <= ?", q)
+	assert.Equal(t, []any{int64(3), "123456789012345678901234567890", "0"}, v)
+
+	_, _, e = ToSQL(filter{Count: json.Number("many")})
+	assert.ErrorIs(t, e, ErrUnsupportedType)
+	assert.ErrorContains(t, e, `field Count (column count): unsupported type: invalid json.Number "many"`)
+}
+
+func TestToSQLWithTagName(t *testing.T) {
+	type filter struct {
+		Name   *string `qf:"name"`
+		MinAge *int    `qf:"age,op=gt" filter:"ignored,op=lt"`
+		Title  *string `filter:"title"`
+	}
+
+	name, age, title := "jane", 18, "lead"
+	f := filter{Name: &name, MinAge: &age, Title: &title}
+
+	q, v, e := ToSQL(f, WithTagName("qf"))
+	assert.Nil(t, e)
+	assert.Equal(t, "name = ? AND age > ?", q)
+	assert.Equal(t, []any{name, int64(age)}, v)
+
+	// the global tag name is used otherwise
+	q, _, e = ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "ignored < ? AND title = ?", q)
+
+	_, _, e = ToSQL(f, WithTagName("qf"), WithStrictFields(true))
+	assert.ErrorContains(t, e, "field Title is missing a qf tag")
+}
+
+func TestToSQLWithValueTransformer(t *testing.T) {
+	type filter struct {
+		Status   *string  `filter:"status"`
+		Statuses []string `filter:"status,op=in"`
+		Name     *string  `filter:"name"`
+	}
+
+	status, name := "  TODO ", "Jane"
+	f := filter{Status: &status, Statuses: []string{"Doing", " DONE"}, Name: &name}
+
+	var cols []string
+	q, v, e := ToSQL(f, WithValueTransformer(func(col string, v any) any {
+		cols = append(cols, col)
+		if s, ok := v.(string); ok && col == "status" {
+			return strings.ToLower(strings.TrimSpace(s))
+		}
+		return v
+	}))
+
+	assert.Nil(t, e)
+	assert.Equal(t, "status = ? AND status IN(?,?) AND name = ?", q)
+	assert.Equal(t, []any{"todo", "doing", "done", "Jane"}, v)
+	assert.Equal(t, []string{"status", "status", "status", "name"}, cols)
+}
+
+func TestToSQLWithValueTransformerInGroups(t *testing.T) {
+	type inner struct {
+		Status *string  `filter:"status"`
+		Tags   []string `filter:"tags,op=array-overlap"`
+	}
+
+	type filter struct {
+		Group  inner   `filter:",group"`
+		Groups []inner `filter:"conditions,op=groups"`
+	}
+
+	status := "TODO"
+	f := filter{Group: inner{Status: &status, Tags: []string{"go"}}, Groups: []inner{{Status: &status, Tags: []string{"sql"}}}}
+
+	var cols []string
+	q, v, e := ToSQL(f,
+		WithValueTransformer(func(col string, v any) any {
+			cols = append(cols, col)
+			if s, ok := v.(string); ok {
+				return strings.ToLower(s)
+			}
+			return v
+		}),
+		WithArrayBinder(func(v any) any { return fmt.Sprint(v) }),
+	)
+
+	assert.Nil(t, e)
+	assert.Equal(t, "(status = ? AND tags && ?) AND ((status = ? AND tags && ?))", q)
+	assert.Equal(t, []any{"todo", "[go]", "todo", "[sql]"}, v)
+	assert.Equal(t, []string{"status", "tags", "status", "tags"}, cols)
+}
+
+func TestToSQLMapAsJSON(t *testing.T) {
+	type filter struct {
+		Metadata map[string]string `filter:"metadata"`
+	}
+
+	q, v, e := ToSQL(filter{Metadata: map[string]string{"country": "NL"}})
+	assert.Nil(t, e)
+	assert.Equal(t, "metadata = ?", q)
+	assert.Equal(t, []any{`{"country":"NL"}`}, v)
+
+	q, v, e = ToSQL(filter{})
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+}
+
+type SKU struct {
+	Category string
+	Number   int
+}
+
+func (s SKU) String() string {
+	return fmt.Sprintf("%s-%04d", s.Category, s.Number)
+}
+
+type Barcode struct {
+	Digits string
+}
+
+func (b *Barcode) String() string {
+	return "EAN" + b.Digits
+}
+
+func TestToSQLStringerStructs(t *testing.T) {
+	type filter struct {
+		SKU      SKU       `filter:"sku"`
+		Barcode  *Barcode  `filter:"barcode"`
+		Excluded []SKU     `filter:"sku,op=not-in"`
+		Since    time.Time `filter:"created_at,op=after"`
+	}
+
+	since := time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC)
+	f := filter{
+		SKU:      SKU{Category: "TS", Number: 42},
+		Barcode:  &Barcode{Digits: "871"},
+		Excluded: []SKU{{Category: "TS", Number: 1}},
+		Since:    since,
+	}
+
+	q, v, e := ToSQL(f)
+	assert.Nil(t, e)
+	assert.Equal(t, "sku = ? AND barcode = ? AND sku NOT IN(?) AND created_at > ?", q)
+	assert.Equal(t, []any{"TS-0042", "EAN871", "TS-0001", since}, v)
+
+	type unsupported struct {
+		Range struct{ Min, Max int } `filter:"range"`
+	}
+
+	_, _, e = ToSQL(unsupported{})
+	assert.ErrorIs(t, e, ErrUnsupportedType)
+	assert.ErrorContains(t, e, "structs are not supported, only time.Time and fmt.Stringer")
+}
+
+func TestParseTagMalformed(t *testing.T) {
+	for _, tag := range []string{",,op=", "name,op=", "name,=eq", "name,", "name,op=eq,", "op=", "op=in,col="} {
+		_, err := parseTag(tag)
+		assert.ErrorIs(t, err, ErrInvalidTag, tag)
+	}
+}
+
+func FuzzParseTag(f *testing.F) {
+	for _, seed := range []string{
+		"", "name", "name,op=eq", ",,op=", "op=", "name,op=json-path,path=a.b", "=,=", "a,b=c=d", "op=in,col=status",
+		",col=0",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, tag string) {
+		parsed, err := parseTag(tag)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrInvalidTag)
+			return
+		}
+
+		// an explicit column (col= or column=) takes the place of an empty positional column
+		for _, segment := range strings.Split(tag, ",") {
+			key, value, found := strings.Cut(segment, "=")
+			if key = strings.TrimSpace(key); found && (key == "col" || key == "column") {
+				assert.Equal(t, strings.TrimSpace(value), parsed.column)
+				return
+			}
+		}
+
+		// a positional column is the part leading up to the first comma
+		if col, _, _ := strings.Cut(tag, ","); !strings.Contains(col, "=") {
+			assert.Equal(t, col, parsed.column)
+		}
+	})
+}
+
+func TestParseTagExplicitColumn(t *testing.T) {
+	table := []struct {
+		tag    string
+		expect parsedTag
+	}{
+		{"status,op=in", parsedTag{column: "status", operator: "in"}},
+		{"op=in,col=status", parsedTag{column: "status", operator: "in"}},
+		{"column=status,op=in", parsedTag{column: "status", operator: "in"}},
+		{"path=a.b,op=json-path,col=metadata", parsedTag{
+			column: "metadata", operator: "json-path", params: map[string]string{"path": "a.b"},
+		}},
+		{"op=in", parsedTag{operator: "in"}},
+	}
+
+	for _, tc := range table {
+		parsed, err := parseTag(tc.tag)
+		assert.Nil(t, err, tc.tag)
+		assert.Equal(t, tc.expect, parsed, tc.tag)
+	}
+
+	_, err := parseTag("status,col=state")
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	assert.ErrorContains(t, err, "ambiguous column")
+}
+
+func TestToSQLExplicitColumn(t *testing.T) {
+	type filter struct {
+		Statuses []string `filter:"op=in,col=status"`
+	}
+
+	q, v, e := ToSQL(filter{Statuses: []string{"todo", "done"}})
+	assert.Nil(t, e)
+	assert.Equal(t, "status IN(?,?)", q)
+	assert.Equal(t, []any{"todo", "done"}, v)
+
+	type missingColumn struct {
+		Name *string `filter:"op=eq"`
+	}
+
+	_, _, e = ToSQL(missingColumn{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "missing column")
+}
@@ -0,0 +1,28 @@
+package queryfilter
+
+// Sqlizer renders a filter on demand, satisfying the Sqlizer interface of github.com/Masterminds/squirrel
+// without this package depending on it. This allows composing filters into queries built using squirrel, eg:
+//
+//	query := squirrel.Select("*").From("tasks").Where(queryfilter.AsSqlizer(filter))
+//
+// The clauses are rendered using questionmarks, which squirrel rewrites using the placeholder format
+// of the query (eg: squirrel.Dollar) so the placeholders are numbered across the whole query.
+type Sqlizer struct {
+	filter any
+	fns    []OptFn
+}
+
+// AsSqlizer returns a Sqlizer for the filter, rendered using the given options.
+// The placeholder strategy is always set to PlaceholderStrategyQuestionmark.
+func AsSqlizer(f any, fns ...OptFn) Sqlizer {
+	return Sqlizer{filter: f, fns: fns}
+}
+
+// ToSql returns the query fragment and args of the filter. A filter without any clauses
+// results in an empty fragment, which squirrel leaves out of the query.
+//
+//nolint:revive // named after the method of the squirrel.Sqlizer interface
+func (s Sqlizer) ToSql() (string, []any, error) {
+	fns := append(append([]OptFn{}, s.fns...), WithPlaceholderStrategy(PlaceholderStrategyQuestionmark))
+	return ToSQL(s.filter, fns...)
+}
@@ -0,0 +1,35 @@
+package queryfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sqlizer mirrors the Sqlizer interface of github.com/Masterminds/squirrel.
+type sqlizer interface {
+	ToSql() (string, []any, error)
+}
+
+func TestAsSqlizer(t *testing.T) {
+	type filter struct {
+		Status *string `filter:"status"`
+		MinAge *int    `filter:"age,op=gte"`
+	}
+
+	status, age := "done", 18
+
+	var s sqlizer = AsSqlizer(filter{Status: &status, MinAge: &age}, WithDialect(DialectPostgres))
+	q, v, e := s.ToSql()
+	assert.Nil(t, e)
+	assert.Equal(t, "status = ? AND age >= ?", q)
+	assert.Equal(t, []any{status, int64(age)}, v)
+
+	q, v, e = AsSqlizer(filter{}).ToSql()
+	assert.Nil(t, e)
+	assert.Equal(t, "", q)
+	assert.Empty(t, v)
+
+	_, _, e = AsSqlizer(1).ToSql()
+	assert.ErrorIs(t, e, ErrNotStruct)
+}
@@ -2,10 +2,24 @@ package queryfilter
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 )
 
+// FormatKinds joins the kinds into a human readable list, eg: "int, string or slice".
+// Custom operators can use it to phrase errors consistently with Clause.AssertTypeOneOf.
+func FormatKinds(kinds ...reflect.Kind) string {
+	return summarize(kinds...)
+}
+
+// summarize joins the items into a human readable list, eg: "apple, banana or melon".
 func summarize[T fmt.Stringer](items ...T) string {
+	return summarizeWith("or", items...)
+}
+
+// summarizeWith joins the items into a human readable list using the given conjunction
+// to join the last item, eg: "apple, banana and melon".
+func summarizeWith[T fmt.Stringer](conjunction string, items ...T) string {
 	if len(items) == 1 {
 		return items[0].String()
 	}
@@ -17,7 +31,7 @@ func summarize[T fmt.Stringer](items ...T) string {
 		}
 
 		if i == len(items)-1 {
-			b.WriteString(" or ")
+			b.WriteString(" " + conjunction + " ")
 		}
 
 		b.WriteString(k.String())
@@ -1,6 +1,7 @@
 package queryfilter
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -40,3 +41,13 @@ func TestSummarize(t *testing.T) {
 		assert.Equal(t, tc.e, actual)
 	}
 }
+
+func TestSummarizeWith(t *testing.T) {
+	actual := summarizeWith("and", []Fruit{"apple", "banana", "melon"}...)
+	assert.Equal(t, "apple, banana and melon", actual)
+}
+
+func TestFormatKinds(t *testing.T) {
+	assert.Equal(t, "int, string or slice", FormatKinds(reflect.Int, reflect.String, reflect.Slice))
+	assert.Equal(t, "bool", FormatKinds(reflect.Bool))
+}
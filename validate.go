@@ -0,0 +1,199 @@
+package queryfilter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidationError holds all problems Validate found with the tags of a filter struct.
+type ValidationError struct {
+	Errs []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the problems matches the target, so the sentinel errors
+// can be checked for using errors.Is.
+func (e *ValidationError) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks the tags of the filter struct without reading any of its values, verifying each tag
+// is well-formed and references a registered operator. This allows catching mistakes (eg: a typo in
+// the name of an operator) once at startup, rather than when handling a request, eg:
+//
+//	if err := queryfilter.Validate(TaskFilter{}); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The source methods and the parameters the operators require (eg: the path of json-path) are checked as well,
+// and the filter structs rendered by groups (see the group flag and the groups operator) are checked recursively.
+// The prototype is either a struct or a (nil) pointer to one. All problems are returned at once
+// as a *ValidationError.
+func Validate(prototype any, fns ...OptFn) error {
+	opts := DefaultOpts()
+	for _, fn := range fns {
+		fn(opts)
+	}
+
+	if err := validateOpts(opts); err != nil {
+		return err
+	}
+
+	t := reflect.TypeOf(prototype)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("unable to validate filter: %w", ErrNotStruct)
+	}
+
+	if errs := validateStruct(t, opts, map[reflect.Type]bool{}); len(errs) > 0 {
+		return &ValidationError{Errs: errs}
+	}
+
+	return nil
+}
+
+// validateStruct checks the tags of the fields of the filter struct type, including the filter structs rendered
+// by its groups, following the same rules as rangeClauses. Types that are being checked already (eg: a filter
+// holding groups of itself) are skipped, so recursive filters don't recurse endlessly.
+func validateStruct(t reflect.Type, opts *Opts, seen map[reflect.Type]bool) []error {
+	if seen[t] {
+		return nil
+	}
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	var (
+		errs []error
+
+		// the indexes of the group fields, whose promoted fields are checked as part of the group instead
+		groups [][]int
+	)
+
+	for _, field := range reflect.VisibleFields(t) {
+		if withinGroup(field.Index, groups) {
+			continue
+		}
+
+		nested, err := validateField(t, field, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %s: %w", field.Name, err))
+			continue
+		}
+
+		if nested == nil {
+			continue
+		}
+
+		groups = append(groups, field.Index)
+		for _, err := range validateStruct(nested, opts, seen) {
+			errs = append(errs, fmt.Errorf("field %s: %w", field.Name, err))
+		}
+	}
+
+	return errs
+}
+
+// validateField checks the tag of a single field of the filter struct type, following the same rules as
+// buildClauses. It returns the filter struct type rendered by the field when it's a group, see groupFilterType.
+func validateField(t reflect.Type, field reflect.StructField, opts *Opts) (reflect.Type, error) {
+	tag, ok := field.Tag.Lookup(opts.TagName)
+	if !ok {
+		if opts.StrictFields && field.IsExported() && !field.Anonymous {
+			return nil, fmt.Errorf("missing a %s tag", opts.TagName)
+		}
+		return nil, nil
+	}
+
+	if tag == skipTag {
+		return nil, nil
+	}
+
+	if !field.IsExported() {
+		return nil, ErrUnexportedField
+	}
+
+	parsed, err := parseTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	_, operator, err := resolveClause(field, parsed, tag, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if source, ok := parsed.params["source"]; ok {
+		if _, err := checkSource(t, source); err != nil {
+			return nil, err
+		}
+	}
+
+	// the default applies to the value of the source method when set, whose type isn't checked here
+	if value, ok := parsed.params["default"]; ok && parsed.params["source"] == "" {
+		if _, err := readDefault(value, field.Type); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := findOperator(operator, opts); !ok {
+		return nil, fmt.Errorf("operator %s: %w", operator, ErrUnknownOperator)
+	}
+
+	// the path is part of the tag, so it's checked upfront rather than when rendering the clause
+	if operator == "json-path" {
+		if _, err := readJSONPath(operator, parsed.params); err != nil {
+			return nil, err
+		}
+	}
+
+	return groupFilterType(field.Type, operator), nil
+}
+
+// groupFilterType returns the filter struct type rendered by the group (or groups) operator, or nil for other
+// operators. The groups operator renders the elements of a slice or array of filter structs.
+func groupFilterType(t reflect.Type, operator string) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch operator {
+	case "group", "not-group":
+	case "groups":
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		t = t.Elem()
+	default:
+		return nil
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return t
+}
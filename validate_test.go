@@ -0,0 +1,145 @@
+package queryfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	type filter struct {
+		Statuses  []string `filter:"status,op=in"`
+		MinPoints *int     `filter:"story_points,op=gte"`
+		Name      *string  `filter:"name"`
+		Skipped   string   `filter:"-"`
+		Untagged  string
+	}
+
+	assert.Nil(t, Validate(filter{}))
+	assert.Nil(t, Validate(&filter{}))
+	assert.Nil(t, Validate((*filter)(nil)))
+}
+
+func TestValidateDefault(t *testing.T) {
+	type valid struct {
+		Limit int `filter:"limit,op=lte,default=100"`
+	}
+
+	assert.Nil(t, Validate(valid{}))
+
+	type invalid struct {
+		Limit int `filter:"limit,op=lte,default=many"`
+	}
+
+	e := Validate(invalid{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, `field Limit: incorrectly formatted tag: default "many" for int`)
+}
+
+func TestValidateJoinsProblems(t *testing.T) {
+	type filter struct {
+		MinPoints *int    `filter:"story_points,op=gtee"`
+		Name      *string `filter:"name,unknown"`
+		Title     *string `filter:",op=eq"`
+		Status    *string `filter:"status"`
+	}
+
+	e := Validate(filter{})
+	assert.ErrorIs(t, e, ErrUnknownOperator)
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.EqualError(t, e, "field MinPoints: operator gtee: operator is not available; "+
+		"field Name: incorrectly formatted tag: name,unknown; "+
+		"field Title: incorrectly formatted tag: missing column: ,op=eq")
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, e, &validationErr)
+	assert.Len(t, validationErr.Errs, 3)
+}
+
+func TestValidateOptions(t *testing.T) {
+	type filter struct {
+		Name  *string `filter:"name,op=custom"`
+		Title *string
+	}
+
+	assert.ErrorIs(t, Validate(filter{}), ErrUnknownOperator)
+	assert.Nil(t, Validate(filter{}, WithOperator("custom", SimpleOperator("= ?"))))
+
+	e := Validate(filter{}, WithOperator("custom", SimpleOperator("= ?")), WithStrictFields(true))
+	assert.ErrorContains(t, e, "field Title: missing a filter tag")
+
+	assert.ErrorIs(t, Validate("not a struct"), ErrNotStruct)
+	assert.ErrorIs(t, Validate(nil), ErrNotStruct)
+}
+
+type validateSourceFilter struct {
+	Status *string `filter:"status,source=CurrentStatus"`
+	Owner  *string `filter:"owner,source=CurrentOwner"`
+}
+
+func (validateSourceFilter) CurrentStatus() *string { return nil }
+
+func (*validateSourceFilter) CurrentOwner() (*string, error) { return nil, nil }
+
+func TestValidateSource(t *testing.T) {
+	assert.Nil(t, Validate(validateSourceFilter{}))
+
+	type misspelled struct {
+		Status *string `filter:"status,source=CurrentStatuss"`
+	}
+
+	e := Validate(misspelled{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "field Status: incorrectly formatted tag: source method CurrentStatuss not found")
+}
+
+func TestValidateGroups(t *testing.T) {
+	type condition struct {
+		Status *string `filter:"status,op=eqq"`
+	}
+
+	type filter struct {
+		Group      condition    `filter:",group"`
+		Conditions []*condition `filter:"conditions,op=groups"`
+	}
+
+	e := Validate(filter{})
+	assert.ErrorIs(t, e, ErrUnknownOperator)
+	assert.EqualError(t, e, "field Group: field Status: operator eqq: operator is not available; "+
+		"field Conditions: field Status: operator eqq: operator is not available")
+
+	type Condition struct {
+		Status *string `filter:"status,op=eqq"`
+	}
+
+	type embedded struct {
+		Condition `filter:",group"`
+	}
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, Validate(embedded{}), &validationErr)
+	assert.Len(t, validationErr.Errs, 1)
+
+	type recursive struct {
+		Name *string     `filter:"name"`
+		Or   []recursive `filter:"or,op=groups"`
+	}
+
+	assert.Nil(t, Validate(recursive{}))
+}
+
+func TestValidateJSONPath(t *testing.T) {
+	type valid struct {
+		Country *string `filter:"metadata,op=json-path,path=address.country"`
+	}
+
+	assert.Nil(t, Validate(valid{}))
+
+	type missingPath struct {
+		Country *string `filter:"metadata,op=json-path"`
+	}
+
+	e := Validate(missingPath{})
+	assert.ErrorIs(t, e, ErrInvalidTag)
+	assert.ErrorContains(t, e, "field Country: incorrectly formatted tag: operation json-path expects a path")
+}